@@ -0,0 +1,50 @@
+//
+// Package monitorit
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  backend
+// @Date: 2026/10/17 17:05
+//
+
+package monitorit
+
+import "context"
+
+type (
+	// Counter is a metric that only goes up.
+	Counter interface {
+		Add(ctx context.Context, value float64, labelValues ...string)
+	}
+
+	// UpDownCounter is a metric that goes up and down, like a Prometheus gauge.
+	UpDownCounter interface {
+		Add(ctx context.Context, value float64, labelValues ...string)
+	}
+
+	// Histogram is a metric sampling observations into buckets.
+	Histogram interface {
+		Observe(ctx context.Context, value float64, labelValues ...string)
+	}
+
+	// MetricOpts describes a metric created by a Backend.
+	MetricOpts struct {
+		// Name is the Prometheus name, the namespace and subsystem are prepended by the backend.
+		Name string
+		// OTelName is the OpenTelemetry instrument name, aligned with the semantic conventions
+		// when there is one, e.g. "db.client.operation.duration".
+		OTelName string
+		Help     string
+		// Unit is the UCUM unit of the OpenTelemetry instrument, e.g. "s" or "{row}".
+		Unit       string
+		LabelNames []string
+		Buckets    []float64
+	}
+
+	// Backend creates the metrics recorded by the hooks, callbacks and middlewares, decoupling
+	// them from the metrics library.
+	Backend interface {
+		NewCounter(opts MetricOpts) (Counter, error)
+		NewUpDownCounter(opts MetricOpts) (UpDownCounter, error)
+		NewHistogram(opts MetricOpts) (Histogram, error)
+	}
+)
@@ -0,0 +1,117 @@
+//
+// Package monitorit
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  backend_otel
+// @Date: 2026/10/17 17:05
+//
+
+package monitorit
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// otelAttributeKeys maps the label names to the attribute keys of the semantic conventions,
+// labels missing here keep their name, e.g. the Kratos transport kind, which isn't the RPC system
+// of HTTP requests. No two labels map to the same key, their values would be lost.
+var otelAttributeKeys = map[string]string{
+	"db_name":       "db.namespace",
+	"instance_name": "db.client.connection.pool.name",
+	"command":       "db.operation.name",
+	"table":         "db.collection.name",
+	"error":         "error.type",
+	"operation":     "rpc.method",
+	"code":          "rpc.response.status_code",
+	"endpoint":      "server.address",
+	"channel":       "messaging.destination.name",
+}
+
+type (
+	openTelemetryBackend struct {
+		meter metric.Meter
+	}
+
+	openTelemetryCounter struct {
+		counter metric.Float64Counter
+		keys    []attribute.Key
+	}
+
+	openTelemetryUpDownCounter struct {
+		counter metric.Float64UpDownCounter
+		keys    []attribute.Key
+	}
+
+	openTelemetryHistogram struct {
+		histogram metric.Float64Histogram
+		keys      []attribute.Key
+	}
+)
+
+// NewOpenTelemetryBackend returns a backend creating instruments with the meter of provider
+// named after the instrumentation scope.
+func NewOpenTelemetryBackend(provider metric.MeterProvider, scope string) Backend {
+	return &openTelemetryBackend{meter: provider.Meter(scope)}
+}
+
+func (b *openTelemetryBackend) NewCounter(opts MetricOpts) (Counter, error) {
+	counter, err := b.meter.Float64Counter(opts.OTelName, metric.WithDescription(opts.Help), metric.WithUnit(opts.Unit))
+	if err != nil {
+		return nil, err
+	}
+	return openTelemetryCounter{counter: counter, keys: attributeKeys(opts.LabelNames)}, nil
+}
+
+func (b *openTelemetryBackend) NewUpDownCounter(opts MetricOpts) (UpDownCounter, error) {
+	counter, err := b.meter.Float64UpDownCounter(opts.OTelName, metric.WithDescription(opts.Help), metric.WithUnit(opts.Unit))
+	if err != nil {
+		return nil, err
+	}
+	return openTelemetryUpDownCounter{counter: counter, keys: attributeKeys(opts.LabelNames)}, nil
+}
+
+func (b *openTelemetryBackend) NewHistogram(opts MetricOpts) (Histogram, error) {
+	histogramOpts := []metric.Float64HistogramOption{metric.WithDescription(opts.Help), metric.WithUnit(opts.Unit)}
+	if len(opts.Buckets) > 0 {
+		histogramOpts = append(histogramOpts, metric.WithExplicitBucketBoundaries(opts.Buckets...))
+	}
+	histogram, err := b.meter.Float64Histogram(opts.OTelName, histogramOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return openTelemetryHistogram{histogram: histogram, keys: attributeKeys(opts.LabelNames)}, nil
+}
+
+func (c openTelemetryCounter) Add(ctx context.Context, value float64, labelValues ...string) {
+	c.counter.Add(ctx, value, metric.WithAttributes(attributes(c.keys, labelValues)...))
+}
+
+func (c openTelemetryUpDownCounter) Add(ctx context.Context, value float64, labelValues ...string) {
+	c.counter.Add(ctx, value, metric.WithAttributes(attributes(c.keys, labelValues)...))
+}
+
+func (h openTelemetryHistogram) Observe(ctx context.Context, value float64, labelValues ...string) {
+	h.histogram.Record(ctx, value, metric.WithAttributes(attributes(h.keys, labelValues)...))
+}
+
+func attributeKeys(labelNames []string) []attribute.Key {
+	keys := make([]attribute.Key, len(labelNames))
+	for i, name := range labelNames {
+		if key, ok := otelAttributeKeys[name]; ok {
+			name = key
+		}
+		keys[i] = attribute.Key(name)
+	}
+	return keys
+}
+
+func attributes(keys []attribute.Key, labelValues []string) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, len(keys))
+	for i, key := range keys {
+		attrs[i] = key.String(labelValues[i])
+	}
+	return attrs
+}
@@ -0,0 +1,80 @@
+package monitorit
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestOpenTelemetryBackend(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	backend := NewOpenTelemetryBackend(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)), "test")
+	ctx := context.Background()
+
+	labelNames := []string{"custom"}
+	labelValues := []string{"value"}
+	for name := range otelAttributeKeys {
+		labelNames = append(labelNames, name)
+		labelValues = append(labelValues, name+"_value")
+	}
+	counter, err := backend.NewCounter(MetricOpts{Name: "requests_total", OTelName: "test.requests", LabelNames: labelNames})
+	if err != nil {
+		t.Fatalf("NewCounter() error = %v", err)
+	}
+	counter.Add(ctx, 1, labelValues...)
+	upDownCounter, err := backend.NewUpDownCounter(MetricOpts{Name: "active", OTelName: "test.active", LabelNames: labelNames})
+	if err != nil {
+		t.Fatalf("NewUpDownCounter() error = %v", err)
+	}
+	upDownCounter.Add(ctx, 1, labelValues...)
+	histogram, err := backend.NewHistogram(MetricOpts{Name: "duration_seconds", OTelName: "test.duration", Unit: "s", LabelNames: labelNames, Buckets: []float64{1}})
+	if err != nil {
+		t.Fatalf("NewHistogram() error = %v", err)
+	}
+	histogram.Observe(ctx, 0.5, labelValues...)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(rm.ScopeMetrics) != 1 || rm.ScopeMetrics[0].Scope.Name != "test" {
+		t.Fatalf("ScopeMetrics = %+v, want the test scope", rm.ScopeMetrics)
+	}
+	attrs := make(map[string]attribute.Set)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[float64]:
+			attrs[m.Name] = data.DataPoints[0].Attributes
+		case metricdata.Histogram[float64]:
+			attrs[m.Name] = data.DataPoints[0].Attributes
+		}
+	}
+	for _, name := range []string{"test.requests", "test.active", "test.duration"} {
+		set, ok := attrs[name]
+		if !ok {
+			t.Errorf("instrument %s wasn't collected", name)
+			continue
+		}
+		if value, ok := set.Value("custom"); !ok || value.AsString() != "value" {
+			t.Errorf("%s attribute custom = %v, want the unmapped label", name, value)
+		}
+		for label, key := range otelAttributeKeys {
+			if value, ok := set.Value(attribute.Key(key)); !ok || value.AsString() != label+"_value" {
+				t.Errorf("%s attribute %s = %v, want the value of the label %s", name, key, value, label)
+			}
+		}
+	}
+}
+
+func TestOpenTelemetryAttributeKeys(t *testing.T) {
+	labels := make(map[string]string)
+	for label, key := range otelAttributeKeys {
+		if other, ok := labels[key]; ok {
+			t.Errorf("the labels %s and %s both map to the attribute %s", label, other, key)
+		}
+		labels[key] = label
+	}
+}
@@ -0,0 +1,108 @@
+//
+// Package monitorit
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  backend_prometheus
+// @Date: 2026/10/17 17:05
+//
+
+package monitorit
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusOptions represents options of the Prometheus backend.
+type PrometheusOptions struct {
+	Namespace  string
+	Subsystem  string
+	Registerer prometheus.Registerer
+
+	NativeHistogramBucketFactor float64
+	NativeHistogramMaxBuckets   uint32
+	TraceIDExtractor            TraceIDExtractor
+}
+
+type (
+	prometheusBackend struct {
+		options PrometheusOptions
+	}
+
+	prometheusCounter struct {
+		vec *prometheus.CounterVec
+	}
+
+	prometheusUpDownCounter struct {
+		vec *prometheus.GaugeVec
+	}
+
+	prometheusHistogram struct {
+		vec       *prometheus.HistogramVec
+		extractor TraceIDExtractor
+	}
+)
+
+// NewPrometheusBackend returns a backend creating Prometheus collectors and registering them.
+// Collectors created twice share the registered one.
+func NewPrometheusBackend(options PrometheusOptions) Backend {
+	return &prometheusBackend{options: options}
+}
+
+func (b *prometheusBackend) NewCounter(opts MetricOpts) (Counter, error) {
+	vec, err := RegisterAs(b.options.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: b.options.Namespace,
+		Subsystem: b.options.Subsystem,
+		Name:      opts.Name,
+		Help:      opts.Help,
+	}, opts.LabelNames))
+	if err != nil {
+		return nil, err
+	}
+	return prometheusCounter{vec: vec}, nil
+}
+
+func (b *prometheusBackend) NewUpDownCounter(opts MetricOpts) (UpDownCounter, error) {
+	vec, err := RegisterAs(b.options.Registerer, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: b.options.Namespace,
+		Subsystem: b.options.Subsystem,
+		Name:      opts.Name,
+		Help:      opts.Help,
+	}, opts.LabelNames))
+	if err != nil {
+		return nil, err
+	}
+	return prometheusUpDownCounter{vec: vec}, nil
+}
+
+func (b *prometheusBackend) NewHistogram(opts MetricOpts) (Histogram, error) {
+	vec, err := RegisterAs(b.options.Registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:                      b.options.Namespace,
+		Subsystem:                      b.options.Subsystem,
+		Name:                           opts.Name,
+		Help:                           opts.Help,
+		Buckets:                        opts.Buckets,
+		NativeHistogramBucketFactor:    b.options.NativeHistogramBucketFactor,
+		NativeHistogramMaxBucketNumber: b.options.NativeHistogramMaxBuckets,
+	}, opts.LabelNames))
+	if err != nil {
+		return nil, err
+	}
+	return prometheusHistogram{
+		vec:       vec,
+		extractor: b.options.TraceIDExtractor,
+	}, nil
+}
+
+func (c prometheusCounter) Add(_ context.Context, value float64, labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Add(value)
+}
+
+func (c prometheusUpDownCounter) Add(_ context.Context, value float64, labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Add(value)
+}
+
+func (h prometheusHistogram) Observe(ctx context.Context, value float64, labelValues ...string) {
+	Observe(ctx, h.vec.WithLabelValues(labelValues...), value, h.extractor)
+}
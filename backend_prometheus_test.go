@@ -0,0 +1,65 @@
+package monitorit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// gatherHistogram returns the first series of the histogram called name.
+func gatherHistogram(t *testing.T, registry *prometheus.Registry, name string) *dto.Histogram {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetHistogram()
+		}
+	}
+	t.Fatalf("%s wasn't gathered", name)
+	return nil
+}
+
+func TestPrometheusBackendNativeHistograms(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		bucketFactor float64
+		wantNative   bool
+	}{
+		{"classic", 0, false},
+		{"native", 1.1, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			backend := NewPrometheusBackend(PrometheusOptions{
+				Namespace:                   "test",
+				Registerer:                  registry,
+				NativeHistogramBucketFactor: tt.bucketFactor,
+				NativeHistogramMaxBuckets:   100,
+			})
+			histogram, err := backend.NewHistogram(MetricOpts{Name: "duration_seconds", Help: "Duration", Buckets: []float64{.1, 1}})
+			if err != nil {
+				t.Fatalf("NewHistogram() error = %v", err)
+			}
+			for _, value := range []float64{.05, .5, 5} {
+				histogram.Observe(context.Background(), value)
+			}
+
+			h := gatherHistogram(t, registry, "test_duration_seconds")
+			// The classic buckets are kept for the servers without native histograms
+			if got := len(h.GetBucket()); got != 2 {
+				t.Errorf("classic buckets = %d, want 2", got)
+			}
+			if native := h.Schema != nil; native != tt.wantNative {
+				t.Errorf("native histogram = %v, want %v", native, tt.wantNative)
+			}
+			if tt.wantNative && len(h.GetPositiveSpan()) == 0 {
+				t.Error("the native histogram has no positive span")
+			}
+		})
+	}
+}
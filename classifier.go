@@ -0,0 +1,204 @@
+//
+// Package monitorit
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  classifier
+// @Date: 2026/10/17 10:40
+//
+
+package monitorit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Error classes returned by the built-in classifiers.
+const (
+	ErrorClassTimeout      = "timeout"
+	ErrorClassCanceled     = "canceled"
+	ErrorClassNotFound     = "not_found" // The row, key or script looked up doesn't exist, e.g. sql.ErrNoRows.
+	ErrorClassDuplicateKey = "duplicate_key"
+	ErrorClassDeadlock     = "deadlock"
+	ErrorClassConnection   = "connection"
+	// ErrorClassSyntax is a statement failing whatever the data, as it's malformed or refers to
+	// tables, columns or privileges missing from the database, e.g. PostgreSQL class 42.
+	ErrorClassSyntax = "syntax"
+	ErrorClassOther  = "other"
+)
+
+type (
+	// ErrorClassifier maps an error to a label value from a small fixed set, so that
+	// error metrics don't embed SQL values, keys or addresses in their labels.
+	ErrorClassifier interface {
+		Classify(err error) string
+	}
+
+	// ErrorClassifierFunc is an adapter to use an ordinary function as ErrorClassifier.
+	ErrorClassifierFunc func(err error) string
+)
+
+// Classify calls f(err).
+func (f ErrorClassifierFunc) Classify(err error) string {
+	return f(err)
+}
+
+// ChainErrorClassifiers returns a classifier asking each classifier in turn,
+// the first class other than ErrorClassOther wins.
+func ChainErrorClassifiers(classifiers ...ErrorClassifier) ErrorClassifier {
+	return ErrorClassifierFunc(func(err error) string {
+		for _, classifier := range classifiers {
+			if class := classifier.Classify(err); class != ErrorClassOther {
+				return class
+			}
+		}
+		return ErrorClassOther
+	})
+}
+
+// DefaultErrorClassifier returns a classifier recognizing context, database/sql and network errors.
+func DefaultErrorClassifier() ErrorClassifier {
+	return ErrorClassifierFunc(classifyCommonError)
+}
+
+// MySQLErrorClassifier returns a classifier recognizing MySQL server error numbers.
+func MySQLErrorClassifier() ErrorClassifier {
+	return ErrorClassifierFunc(classifyMySQLError)
+}
+
+// PostgresErrorClassifier returns a classifier recognizing PostgreSQL SQLSTATE codes,
+// as exposed by both pgx and lib/pq errors.
+func PostgresErrorClassifier() ErrorClassifier {
+	return ErrorClassifierFunc(classifyPostgresError)
+}
+
+// SQLiteErrorClassifier returns a classifier recognizing SQLite error messages.
+func SQLiteErrorClassifier() ErrorClassifier {
+	return ErrorClassifierFunc(classifySQLiteError)
+}
+
+// SQLErrorClassifier returns a classifier combining the default, MySQL, PostgreSQL and SQLite classifiers.
+func SQLErrorClassifier() ErrorClassifier {
+	return ChainErrorClassifiers(
+		DefaultErrorClassifier(),
+		MySQLErrorClassifier(),
+		PostgresErrorClassifier(),
+		SQLiteErrorClassifier(),
+	)
+}
+
+func classifyCommonError(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrorClassNotFound
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE):
+		return ErrorClassConnection
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassConnection
+	}
+	return ErrorClassOther
+}
+
+// mysqlErrorPattern matches the go-sql-driver message format, "Error 1062 (23000): ..." or "Error 1062: ...".
+var mysqlErrorPattern = regexp.MustCompile(`^Error (\d+)`)
+
+func classifyMySQLError(err error) string {
+	msg := err.Error()
+	if match := mysqlErrorPattern.FindStringSubmatch(msg); match != nil {
+		number, _ := strconv.Atoi(match[1])
+		switch number {
+		case 1022, 1062, 1586: // ER_DUP_KEY, ER_DUP_ENTRY, ER_DUP_ENTRY_WITH_KEY_NAME
+			return ErrorClassDuplicateKey
+		case 1213: // ER_LOCK_DEADLOCK
+			return ErrorClassDeadlock
+		case 1205, 3024: // ER_LOCK_WAIT_TIMEOUT, ER_QUERY_TIMEOUT
+			return ErrorClassTimeout
+		case 1317: // ER_QUERY_INTERRUPTED
+			return ErrorClassCanceled
+		case 1064, 1149: // ER_PARSE_ERROR, ER_SYNTAX_ERROR
+			return ErrorClassSyntax
+		case 1051, 1054, 1146, 1142, 1143: // unknown table, unknown column, no such table, table or column access denied
+			return ErrorClassSyntax
+		case 1040, 1053, 2002, 2003, 2006, 2013: // too many connections, shutdown, can't connect, gone away, lost connection
+			return ErrorClassConnection
+		}
+		return ErrorClassOther
+	}
+
+	if strings.Contains(msg, "invalid connection") || strings.Contains(msg, "bad connection") {
+		return ErrorClassConnection
+	}
+	return ErrorClassOther
+}
+
+func classifyPostgresError(err error) string {
+	var pgErr interface {
+		SQLState() string
+	}
+	if !errors.As(err, &pgErr) {
+		return ErrorClassOther
+	}
+
+	code := pgErr.SQLState()
+	switch {
+	case code == "23505": // unique_violation
+		return ErrorClassDuplicateKey
+	case code == "40P01": // deadlock_detected
+		return ErrorClassDeadlock
+	case code == "55P03": // lock_not_available
+		return ErrorClassTimeout
+	case code == "57014": // query_canceled, also raised by statement_timeout
+		if strings.Contains(err.Error(), "timeout") {
+			return ErrorClassTimeout
+		}
+		return ErrorClassCanceled
+	case code == "53300", strings.HasPrefix(code, "08"), strings.HasPrefix(code, "57P"):
+		return ErrorClassConnection
+	case strings.HasPrefix(code, "42"): // syntax error or access rule violation, e.g. undefined_table
+		return ErrorClassSyntax
+	}
+	return ErrorClassOther
+}
+
+func classifySQLiteError(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "UNIQUE constraint failed"), strings.Contains(msg, "PRIMARY KEY must be unique"):
+		return ErrorClassDuplicateKey
+	case strings.Contains(msg, "database is locked"), strings.Contains(msg, "database table is locked"):
+		return ErrorClassDeadlock
+	case strings.Contains(msg, "interrupted"):
+		return ErrorClassCanceled
+	case strings.Contains(msg, "syntax error"), strings.Contains(msg, "incomplete input"),
+		strings.Contains(msg, "no such table"), strings.Contains(msg, "no such column"),
+		strings.Contains(msg, "no such function"), strings.Contains(msg, "has no column named"):
+		return ErrorClassSyntax
+	case strings.Contains(msg, "unable to open database file"):
+		return ErrorClassConnection
+	}
+	return ErrorClassOther
+}
@@ -0,0 +1,88 @@
+package monitorit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// pgError is an error with a SQLSTATE code, like the pgx and lib/pq errors.
+type pgError struct {
+	code    string
+	message string
+}
+
+func (e *pgError) Error() string    { return e.message }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestErrorClassifiers(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		classifier ErrorClassifier
+		err        error
+		want       string
+	}{
+		{"deadline", DefaultErrorClassifier(), context.DeadlineExceeded, ErrorClassTimeout},
+		{"wrapped deadline", DefaultErrorClassifier(), fmt.Errorf("query: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{"canceled", DefaultErrorClassifier(), context.Canceled, ErrorClassCanceled},
+		{"no rows", DefaultErrorClassifier(), sql.ErrNoRows, ErrorClassNotFound},
+		{"bad conn", DefaultErrorClassifier(), driver.ErrBadConn, ErrorClassConnection},
+		{"conn done", DefaultErrorClassifier(), sql.ErrConnDone, ErrorClassConnection},
+		{"refused", DefaultErrorClassifier(), &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, ErrorClassConnection},
+		{"net timeout", DefaultErrorClassifier(), &net.DNSError{IsTimeout: true}, ErrorClassTimeout},
+		{"unknown", DefaultErrorClassifier(), errors.New("boom"), ErrorClassOther},
+
+		{"mysql duplicate", MySQLErrorClassifier(), errors.New("Error 1062 (23000): Duplicate entry 'alice' for key 'name'"), ErrorClassDuplicateKey},
+		{"mysql old format", MySQLErrorClassifier(), errors.New("Error 1062: Duplicate entry 'alice' for key 'name'"), ErrorClassDuplicateKey},
+		{"mysql deadlock", MySQLErrorClassifier(), errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), ErrorClassDeadlock},
+		{"mysql lock wait", MySQLErrorClassifier(), errors.New("Error 1205 (HY000): Lock wait timeout exceeded"), ErrorClassTimeout},
+		{"mysql interrupted", MySQLErrorClassifier(), errors.New("Error 1317 (70100): Query execution was interrupted"), ErrorClassCanceled},
+		{"mysql parse", MySQLErrorClassifier(), errors.New("Error 1064 (42000): You have an error in your SQL syntax"), ErrorClassSyntax},
+		{"mysql no such table", MySQLErrorClassifier(), errors.New("Error 1146 (42S02): Table 'shop.missing' doesn't exist"), ErrorClassSyntax},
+		{"mysql unknown column", MySQLErrorClassifier(), errors.New("Error 1054 (42S22): Unknown column 'missing' in 'field list'"), ErrorClassSyntax},
+		{"mysql gone away", MySQLErrorClassifier(), errors.New("Error 2006: MySQL server has gone away"), ErrorClassConnection},
+		{"mysql invalid connection", MySQLErrorClassifier(), errors.New("invalid connection"), ErrorClassConnection},
+		{"mysql other number", MySQLErrorClassifier(), errors.New("Error 1048 (23000): Column 'name' cannot be null"), ErrorClassOther},
+		{"mysql not an error number", MySQLErrorClassifier(), errors.New("failed with Error 1062"), ErrorClassOther},
+
+		{"postgres unique", PostgresErrorClassifier(), &pgError{"23505", "duplicate key value violates unique constraint"}, ErrorClassDuplicateKey},
+		{"postgres deadlock", PostgresErrorClassifier(), &pgError{"40P01", "deadlock detected"}, ErrorClassDeadlock},
+		{"postgres lock", PostgresErrorClassifier(), &pgError{"55P03", "could not obtain lock"}, ErrorClassTimeout},
+		{"postgres statement timeout", PostgresErrorClassifier(), &pgError{"57014", "canceling statement due to statement timeout"}, ErrorClassTimeout},
+		{"postgres canceled", PostgresErrorClassifier(), &pgError{"57014", "canceling statement due to user request"}, ErrorClassCanceled},
+		{"postgres connection", PostgresErrorClassifier(), &pgError{"08006", "connection failure"}, ErrorClassConnection},
+		{"postgres shutdown", PostgresErrorClassifier(), &pgError{"57P01", "terminating connection due to administrator command"}, ErrorClassConnection},
+		{"postgres too many connections", PostgresErrorClassifier(), &pgError{"53300", "too many connections"}, ErrorClassConnection},
+		{"postgres syntax", PostgresErrorClassifier(), &pgError{"42601", "syntax error at or near"}, ErrorClassSyntax},
+		{"postgres undefined table", PostgresErrorClassifier(), &pgError{"42P01", `relation "missing" does not exist`}, ErrorClassSyntax},
+		{"postgres wrapped", PostgresErrorClassifier(), fmt.Errorf("query: %w", &pgError{"23505", "duplicate key"}), ErrorClassDuplicateKey},
+		{"postgres not null", PostgresErrorClassifier(), &pgError{"23502", "null value violates not-null constraint"}, ErrorClassOther},
+		{"postgres no code", PostgresErrorClassifier(), errors.New("duplicate key"), ErrorClassOther},
+
+		{"sqlite unique", SQLiteErrorClassifier(), errors.New("UNIQUE constraint failed: users.name"), ErrorClassDuplicateKey},
+		{"sqlite locked", SQLiteErrorClassifier(), errors.New("database is locked"), ErrorClassDeadlock},
+		{"sqlite interrupted", SQLiteErrorClassifier(), errors.New("interrupted"), ErrorClassCanceled},
+		{"sqlite syntax", SQLiteErrorClassifier(), errors.New(`near "FROM": syntax error`), ErrorClassSyntax},
+		{"sqlite incomplete", SQLiteErrorClassifier(), errors.New("incomplete input"), ErrorClassSyntax},
+		{"sqlite no such table", SQLiteErrorClassifier(), errors.New("no such table: missing"), ErrorClassSyntax},
+		{"sqlite no such column", SQLiteErrorClassifier(), errors.New("no such column: missing"), ErrorClassSyntax},
+		{"sqlite open", SQLiteErrorClassifier(), errors.New("unable to open database file"), ErrorClassConnection},
+		{"sqlite not null", SQLiteErrorClassifier(), errors.New("NOT NULL constraint failed: users.name"), ErrorClassOther},
+
+		{"chain first match", SQLErrorClassifier(), fmt.Errorf("%w: Error 1062: Duplicate entry", context.DeadlineExceeded), ErrorClassTimeout},
+		{"chain fallback", SQLErrorClassifier(), &pgError{"40P01", "deadlock detected"}, ErrorClassDeadlock},
+		{"chain none", SQLErrorClassifier(), errors.New("boom"), ErrorClassOther},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.classifier.Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
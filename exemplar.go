@@ -0,0 +1,42 @@
+//
+// Package monitorit
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  exemplar
+// @Date: 2026/10/17 16:20
+//
+
+package monitorit
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDExtractor returns the trace ID of ctx, or an empty string if there is none.
+type TraceIDExtractor func(ctx context.Context) string
+
+// OpenTelemetryTraceID returns the trace ID of the sampled OpenTelemetry span of ctx.
+func OpenTelemetryTraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() || !spanContext.IsSampled() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// Observe adds value to observer, with an exemplar linking it to the trace ID of ctx
+// if the extractor finds one.
+func Observe(ctx context.Context, observer prometheus.Observer, value float64, extractor TraceIDExtractor) {
+	if extractor != nil {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			if traceID := extractor(ctx); traceID != "" {
+				exemplarObserver.ObserveWithExemplar(value, prometheus.Labels{"trace_id": traceID})
+				return
+			}
+		}
+	}
+	observer.Observe(value)
+}
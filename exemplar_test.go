@@ -0,0 +1,99 @@
+package monitorit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	traceID = trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanID  = trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+)
+
+// spanContext returns ctx with a remote span of traceID, sampled or not.
+func spanContext(ctx context.Context, sampled bool) context.Context {
+	var flags trace.TraceFlags
+	if sampled {
+		flags = trace.FlagsSampled
+	}
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+		Remote:     true,
+	}))
+}
+
+func TestOpenTelemetryTraceID(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"no span", ctx, ""},
+		{"unsampled span", spanContext(ctx, false), ""},
+		{"sampled span", spanContext(ctx, true), traceID.String()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OpenTelemetryTraceID(tt.ctx); got != tt.want {
+				t.Errorf("OpenTelemetryTraceID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name      string
+		ctx       context.Context
+		extractor TraceIDExtractor
+		want      string
+	}{
+		{"sampled span", spanContext(ctx, true), OpenTelemetryTraceID, traceID.String()},
+		{"unsampled span", spanContext(ctx, false), OpenTelemetryTraceID, ""},
+		{"no extractor", spanContext(ctx, true), nil, ""},
+		{"custom extractor", ctx, func(context.Context) string { return "b7ad6b7169203331" }, "b7ad6b7169203331"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			backend := NewPrometheusBackend(PrometheusOptions{
+				Namespace:        "test",
+				Registerer:       registry,
+				TraceIDExtractor: tt.extractor,
+			})
+			histogram, err := backend.NewHistogram(MetricOpts{Name: "duration_seconds", Help: "Duration", Buckets: []float64{1}})
+			if err != nil {
+				t.Fatalf("NewHistogram() error = %v", err)
+			}
+			histogram.Observe(tt.ctx, .5)
+
+			var got string
+			for _, pair := range gatherHistogram(t, registry, "test_duration_seconds").GetBucket()[0].GetExemplar().GetLabel() {
+				if pair.GetName() == "trace_id" {
+					got = pair.GetValue()
+				}
+			}
+			if got != tt.want {
+				t.Errorf("exemplar trace_id = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// observer is a prometheus.Observer without exemplars.
+type observer []float64
+
+func (o *observer) Observe(value float64) { *o = append(*o, value) }
+
+func TestObserveWithoutExemplars(t *testing.T) {
+	var o observer
+	Observe(spanContext(context.Background(), true), &o, .5, OpenTelemetryTraceID)
+	if len(o) != 1 || o[0] != .5 {
+		t.Errorf("observations = %v, want [0.5]", o)
+	}
+}
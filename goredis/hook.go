@@ -10,12 +10,10 @@ package goredis
 
 import (
 	"context"
-	"fmt"
 	"time"
 
-	"github.com/feymanlee/monitorit"
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics"
 	"github.com/go-redis/redis/v8"
-	"github.com/prometheus/client_golang/prometheus"
 )
 
 type (
@@ -27,70 +25,46 @@ type (
 	//   - Histogram of duration
 	//   - Counter of errors
 	//
+	// - Payloads of commands, see WithPayloadSizes
+	//   - Histogram of argument sizes
+	//   - Histogram of reply sizes
+	//
+	// - Blocking commands, see WithBlockingCommands
+	//   - Histogram of duration
+	//
+	// - Read commands, see WithCacheResults
+	//   - Counter of hits and misses
+	//
+	// - Pub/Sub, see PubSub
+	//   - Counter of messages
+	//   - Gauge of subscriptions
+	//   - Histogram of receive loop latency
+	//
 	// - Pipelined commands
+	//   - Histogram of pipeline sizes
 	//   - Counter of commands
 	//   - Counter of errors
 	//
 	// The duration of individual pipelined commands won't be collected, but the overall duration of the
-	// pipeline will, with a pseudo-command called "pipeline".
+	// pipeline will, with a pseudo-command called "pipeline", or "tx_pipeline" for the MULTI/EXEC
+	// transactions, along with its number of commands. WithAmortizedPipelineDuration additionally
+	// estimates the duration of each pipelined command.
 	Hook struct {
-		options           *Options
-		instanceName      string
-		singleCommands    *prometheus.HistogramVec
-		pipelinedCommands *prometheus.CounterVec
-		singleErrors      *prometheus.CounterVec
-		pipelinedErrors   *prometheus.CounterVec
+		metrics *redismetrics.Metrics
 	}
 
 	startKey struct{}
 )
 
-var (
-	commandLabelNames = []string{"instance_name", "command"}
-	errorLabelNames   = []string{"instance_name", "command", "error"}
-)
-
-// NewHook creates a new go-redis hook instance and registers Prometheus collectors.
-func NewHook(instanceName string, opts ...Option) *Hook {
+// NewHook creates a new go-redis hook instance and its metrics.
+func NewHook(instanceName string, opts ...Option) (*Hook, error) {
 	options := DefaultOptions()
 	options.Merge(opts...)
-	singleCommands := monitorit.Register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: options.Namespace,
-		Subsystem: options.Subsystem,
-		Name:      "single_commands",
-		Help:      "Histogram of single Redis commands",
-		Buckets:   options.DurationBuckets,
-	}, commandLabelNames)).(*prometheus.HistogramVec)
-
-	pipelinedCommands := monitorit.Register(prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: options.Namespace,
-		Subsystem: options.Subsystem,
-		Name:      "pipelined_commands",
-		Help:      "Number of pipelined Redis commands",
-	}, commandLabelNames)).(*prometheus.CounterVec)
-
-	singleErrors := monitorit.Register(prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: options.Namespace,
-		Subsystem: options.Subsystem,
-		Name:      "single_errors",
-		Help:      "Number of single Redis commands that have failed",
-	}, errorLabelNames)).(*prometheus.CounterVec)
-
-	pipelinedErrors := monitorit.Register(prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: options.Namespace,
-		Subsystem: options.Subsystem,
-		Name:      "pipelined_errors",
-		Help:      "Number of pipelined Redis commands that have failed",
-	}, errorLabelNames)).(*prometheus.CounterVec)
-
-	return &Hook{
-		options:           options,
-		instanceName:      instanceName,
-		singleCommands:    singleCommands,
-		pipelinedCommands: pipelinedCommands,
-		singleErrors:      singleErrors,
-		pipelinedErrors:   pipelinedErrors,
+	metrics, err := redismetrics.NewMetrics(instanceName, options, options.Backend("github.com/feymanlee/monitorit/goredis"), redis.Nil)
+	if err != nil {
+		return nil, err
 	}
+	return &Hook{metrics: metrics}, nil
 }
 
 func (hook *Hook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
@@ -99,14 +73,8 @@ func (hook *Hook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.C
 
 func (hook *Hook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
 	if start, ok := ctx.Value(startKey{}).(time.Time); ok {
-		duration := time.Since(start).Seconds()
-		hook.singleCommands.WithLabelValues(hook.instanceName, cmd.Name()).Observe(duration)
-	}
-
-	if isActualErr(cmd.Err()) {
-		hook.singleErrors.WithLabelValues(hook.instanceName, cmd.Name(), fmt.Sprintf("%v", cmd.Err())).Inc()
+		hook.metrics.RecordCommand(ctx, cmd, cmd.Err(), time.Since(start))
 	}
-
 	return nil
 }
 
@@ -115,21 +83,17 @@ func (hook *Hook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder)
 }
 
 func (hook *Hook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
-	if err := hook.AfterProcess(ctx, redis.NewCmd(ctx, "pipeline")); err != nil {
-		return err
-	}
-
-	for _, cmd := range cmds {
-		hook.pipelinedCommands.WithLabelValues(hook.instanceName, cmd.Name()).Inc()
-
-		if isActualErr(cmd.Err()) {
-			hook.pipelinedErrors.WithLabelValues(hook.instanceName, cmd.Name(), fmt.Sprintf("%v", cmd.Err())).Inc()
-		}
+	if start, ok := ctx.Value(startKey{}).(time.Time); ok {
+		hook.metrics.RecordPipeline(ctx, commands(cmds), nil, time.Since(start))
 	}
-
 	return nil
 }
 
-func isActualErr(err error) bool {
-	return err != nil && err != redis.Nil
+// commands returns cmds as the commands the metrics are recorded from.
+func commands(cmds []redis.Cmder) []redismetrics.Cmd {
+	commands := make([]redismetrics.Cmd, len(cmds))
+	for i, cmd := range cmds {
+		commands[i] = cmd
+	}
+	return commands
 }
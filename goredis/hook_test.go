@@ -0,0 +1,79 @@
+package goredis_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/feymanlee/monitorit/goredis"
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics/metricstest"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newClient(t *testing.T, opts ...goredis.Option) (*miniredis.Miniredis, *redis.Client, *goredis.Hook, *prometheus.Registry) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	registry := prometheus.NewRegistry()
+	hook, err := goredis.NewHook("test", append([]goredis.Option{goredis.WithRegisterer(registry)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewHook() error = %v", err)
+	}
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	client.AddHook(hook)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return server, client, hook, registry
+}
+
+// TestHook checks the commands and pipelines are recorded, the metrics themselves are tested
+// with the ones of all go-redis versions.
+func TestHook(t *testing.T) {
+	_, client, _, registry := newClient(t)
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "key", "value", 0).Err(); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := client.Get(ctx, "missing").Err(); err != redis.Nil {
+		t.Fatalf("Get() error = %v, want redis.Nil", err)
+	}
+	if err := client.LPush(ctx, "key", "value").Err(); err == nil {
+		t.Fatal("LPush() on a string succeeded")
+	}
+	if _, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "key")
+		return nil
+	}); err != nil {
+		t.Fatalf("Pipelined() error = %v", err)
+	}
+	if _, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, "counter")
+		return nil
+	}); err != nil {
+		t.Fatalf("TxPipelined() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"service_component_redis_single_commands", map[string]string{"command": "set"}, 1},
+		{"service_component_redis_single_commands", map[string]string{"command": "get"}, 1},
+		{"service_component_redis_single_errors", map[string]string{"command": "get"}, 0},
+		{"service_component_redis_single_errors", map[string]string{"command": "lpush", "error": "other"}, 1},
+		{"service_component_redis_cache_results_total", map[string]string{"command": "get", "result": "miss"}, 1},
+		{"service_component_redis_single_commands", map[string]string{"command": "pipeline"}, 1},
+		{"service_component_redis_single_commands", map[string]string{"command": "tx_pipeline"}, 1},
+		{"service_component_redis_pipelined_commands", map[string]string{"command": "get"}, 1},
+		{"service_component_redis_pipelined_commands", map[string]string{"command": "incr"}, 1},
+		{"service_component_redis_pipelined_commands", map[string]string{"command": "exec"}, 0},
+		{"service_component_redis_cache_results_total", map[string]string{"command": "get", "result": "hit"}, 1},
+	} {
+		if got := metricstest.Value(t, registry, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+//
+// Package rediscmd
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  blocking
+// @Date: 2026/10/17 19:50
+//
+
+package rediscmd
+
+import "strings"
+
+// Blocks returns whether the command called name blocks when it's listed as a blocking
+// command, XREAD and XREADGROUP only block with the BLOCK option.
+func Blocks(name string, args []interface{}) bool {
+	switch name {
+	case "xread", "xreadgroup":
+		for i := 1; i < len(args); i++ {
+			arg := String(args[i])
+			if strings.EqualFold(arg, "streams") {
+				return false
+			}
+			if strings.EqualFold(arg, "block") {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+//
+// Package rediscmd
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  cache
+// @Date: 2026/10/17 19:15
+//
+
+package rediscmd
+
+type readKind int
+
+const (
+	// singleRead commands reply nil on a miss.
+	singleRead readKind = iota + 1
+	// multiRead commands reply an array with a nil element per miss.
+	multiRead
+	// hashRead commands reply an empty map on a miss.
+	hashRead
+)
+
+// readCommands are the read commands whose hits and misses are counted.
+var readCommands = map[string]readKind{
+	"get": singleRead, "getex": singleRead, "getdel": singleRead, "hget": singleRead,
+	"lindex": singleRead, "zscore": singleRead, "zrank": singleRead, "zrevrank": singleRead,
+	"mget": multiRead, "hmget": multiRead,
+	"hgetall": hashRead,
+}
+
+// CacheResults returns the number of hits and misses of a successful read command called name,
+// or one that has failed with nil, false if it isn't a read command.
+func CacheResults(name string, cmd interface{}, isNil bool) (hits int, misses int, ok bool) {
+	switch readCommands[name] {
+	case singleRead:
+		if isNil {
+			return 0, 1, true
+		}
+		return 1, 0, true
+	case multiRead:
+		var values []interface{}
+		switch cmd := cmd.(type) {
+		case interface{ Val() []interface{} }:
+			values = cmd.Val()
+		case interface{ Val() interface{} }:
+			values, _ = cmd.Val().([]interface{})
+		}
+		for _, value := range values {
+			if value == nil {
+				misses++
+			} else {
+				hits++
+			}
+		}
+		return hits, misses, true
+	case hashRead:
+		if isNil || replyLen(cmd) == 0 {
+			return 0, 1, true
+		}
+		return 1, 0, true
+	}
+	return 0, 0, false
+}
+
+// replyLen returns the number of elements of the map or array reply of a command.
+func replyLen(cmd interface{}) int {
+	switch cmd := cmd.(type) {
+	case interface{ Val() map[string]string }:
+		return len(cmd.Val())
+	case interface{ Val() interface{} }:
+		switch value := cmd.Val().(type) {
+		case []interface{}:
+			return len(value)
+		case map[interface{}]interface{}:
+			return len(value)
+		}
+	}
+	return 0
+}
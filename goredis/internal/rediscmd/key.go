@@ -0,0 +1,101 @@
+//
+// Package rediscmd
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  key
+// @Date: 2026/10/17 17:55
+//
+
+package rediscmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keylessCommands are the commands whose first argument isn't a key.
+var keylessCommands = map[string]struct{}{
+	"acl": {}, "auth": {}, "bgrewriteaof": {}, "bgsave": {}, "client": {}, "cluster": {}, "command": {},
+	"config": {}, "dbsize": {}, "debug": {}, "discard": {}, "echo": {}, "exec": {}, "failover": {},
+	"flushall": {}, "flushdb": {}, "function": {}, "hello": {}, "info": {}, "keys": {}, "lastsave": {},
+	"latency": {}, "module": {}, "monitor": {}, "multi": {}, "ping": {}, "pipeline": {}, "psubscribe": {},
+	"publish": {}, "pubsub": {}, "punsubscribe": {}, "quit": {}, "randomkey": {}, "readonly": {},
+	"readwrite": {}, "replicaof": {}, "role": {}, "save": {}, "scan": {}, "script": {}, "select": {},
+	"shutdown": {}, "slaveof": {}, "slowlog": {}, "spublish": {}, "ssubscribe": {}, "subscribe": {},
+	"sunsubscribe": {}, "swapdb": {}, "time": {}, "unsubscribe": {}, "unwatch": {}, "wait": {},
+}
+
+// numkeysPositions are the positions of the numkeys argument of the commands taking the number
+// of their keys before them, e.g. LMPOP numkeys key [key ...] or BLMPOP timeout numkeys key [key ...].
+var numkeysPositions = map[string]int{
+	"eval": 2, "evalsha": 2, "eval_ro": 2, "evalsha_ro": 2, "fcall": 2, "fcall_ro": 2,
+	"lmpop": 1, "zmpop": 1, "sintercard": 1, "zintercard": 1, "zunion": 1, "zinter": 1, "zdiff": 1,
+	"blmpop": 2, "bzmpop": 2,
+}
+
+// FirstKey returns the first key argument of the command called name, false if the command has none.
+func FirstKey(name string, args []interface{}) (string, bool) {
+	pos := 1
+	if numkeys, ok := numkeysPositions[name]; ok {
+		if len(args) <= numkeys || String(args[numkeys]) == "0" {
+			return "", false
+		}
+		pos = numkeys + 1
+	}
+	switch name {
+	case "bitop", "object", "memory", "xinfo", "xgroup":
+		// Subcommand first, e.g. BITOP AND destkey key [key ...] or OBJECT ENCODING key.
+		pos = 2
+	case "xread", "xreadgroup":
+		// XREAD [COUNT count] [BLOCK milliseconds] STREAMS key [key ...] id [id ...]
+		pos = -1
+		for i := 1; i < len(args)-1; i++ {
+			if strings.EqualFold(String(args[i]), "streams") {
+				pos = i + 1
+				break
+			}
+		}
+	default:
+		if _, ok := keylessCommands[name]; ok {
+			return "", false
+		}
+	}
+	if pos < 0 || pos >= len(args) {
+		return "", false
+	}
+	return String(args[pos]), true
+}
+
+// KeyPrefix returns the first depth segments of key split by delimiter, followed by the delimiter
+// and "*" if the key has more segments, e.g. "session:*" for "session:1234" with ":" and 1.
+func KeyPrefix(key, delimiter string, depth int) string {
+	if delimiter == "" || depth <= 0 {
+		return key
+	}
+	end := 0
+	for i := 0; i < depth; i++ {
+		index := strings.Index(key[end:], delimiter)
+		if index == -1 {
+			return key
+		}
+		end += index + len(delimiter)
+	}
+	return key[:end] + "*"
+}
+
+// String returns the string form of a command argument.
+func String(arg interface{}) string {
+	switch arg := arg.(type) {
+	case string:
+		return arg
+	case []byte:
+		return string(arg)
+	case int:
+		return strconv.Itoa(arg)
+	case int64:
+		return strconv.FormatInt(arg, 10)
+	default:
+		return fmt.Sprint(arg)
+	}
+}
@@ -0,0 +1,61 @@
+package rediscmd
+
+import "testing"
+
+func TestFirstKey(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []interface{}
+		want string
+		ok   bool
+	}{
+		{"get", []interface{}{"get", "user:1"}, "user:1", true},
+		{"mset", []interface{}{"mset", "user:1", "alice", "user:2", "bob"}, "user:1", true},
+		{"ping", []interface{}{"ping"}, "", false},
+		{"get", []interface{}{"get"}, "", false},
+		{"bitop", []interface{}{"bitop", "and", "dest:1", "src:1"}, "dest:1", true},
+		{"object", []interface{}{"object", "encoding", "user:1"}, "user:1", true},
+		{"xread", []interface{}{"xread", "count", 2, "streams", "events:1", "0"}, "events:1", true},
+		{"xread", []interface{}{"xread", "count", 2}, "", false},
+		{"eval", []interface{}{"eval", "return 1", 1, "user:1", "arg"}, "user:1", true},
+		{"eval", []interface{}{"eval", "return 1", 0, "arg"}, "", false},
+		{"evalsha", []interface{}{"evalsha", "sha", "2", "user:1", "user:2"}, "user:1", true},
+		{"fcall", []interface{}{"fcall", "fn", 1, "user:1"}, "user:1", true},
+		{"lmpop", []interface{}{"lmpop", 2, "queue:1", "queue:2", "left"}, "queue:1", true},
+		{"zmpop", []interface{}{"zmpop", 1, "rank:1", "min"}, "rank:1", true},
+		{"sintercard", []interface{}{"sintercard", 2, "set:1", "set:2"}, "set:1", true},
+		{"zunion", []interface{}{"zunion", 2, "rank:1", "rank:2"}, "rank:1", true},
+		{"zinter", []interface{}{"zinter", 2, "rank:1", "rank:2"}, "rank:1", true},
+		{"zdiff", []interface{}{"zdiff", 2, "rank:1", "rank:2"}, "rank:1", true},
+		{"zunionstore", []interface{}{"zunionstore", "dest:1", 2, "rank:1", "rank:2"}, "dest:1", true},
+		{"blmpop", []interface{}{"blmpop", 0, 2, "queue:1", "queue:2", "left"}, "queue:1", true},
+		{"bzmpop", []interface{}{"bzmpop", 1.5, 1, "rank:1", "max"}, "rank:1", true},
+		{"blmpop", []interface{}{"blmpop", 0}, "", false},
+	} {
+		got, ok := FirstKey(tt.name, tt.args)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("FirstKey(%q, %v) = %q, %v, want %q, %v", tt.name, tt.args, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestKeyPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		key       string
+		delimiter string
+		depth     int
+		want      string
+	}{
+		{"session:1234", ":", 1, "session:*"},
+		{"app:session:1234", ":", 2, "app:session:*"},
+		{"app:session:1234", ":", 1, "app:*"},
+		{"session", ":", 1, "session"},
+		{"session:1234", ":", 2, "session:1234"},
+		{"session:1234", "", 1, "session:1234"},
+		{"session:1234", ":", 0, "session:1234"},
+	} {
+		if got := KeyPrefix(tt.key, tt.delimiter, tt.depth); got != tt.want {
+			t.Errorf("KeyPrefix(%q, %q, %d) = %q, want %q", tt.key, tt.delimiter, tt.depth, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+//
+// Package rediscmd
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  size
+// @Date: 2026/10/17 18:40
+//
+
+package rediscmd
+
+// ArgsSize returns the size in bytes of the arguments of a command, including its name.
+func ArgsSize(args []interface{}) int {
+	size := 0
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case string:
+			size += len(arg)
+		case []byte:
+			size += len(arg)
+		default:
+			size += len(String(arg))
+		}
+	}
+	return size
+}
+
+// ReplySize returns the size in bytes of the string, slice or map reply of a command,
+// false if the reply is of another type. Only the strings of the reply are counted.
+func ReplySize(cmd interface{}) (int, bool) {
+	switch cmd := cmd.(type) {
+	case interface{ Val() string }:
+		return len(cmd.Val()), true
+	case interface{ Val() []string }:
+		size := 0
+		for _, value := range cmd.Val() {
+			size += len(value)
+		}
+		return size, true
+	case interface{ Val() map[string]string }:
+		size := 0
+		for key, value := range cmd.Val() {
+			size += len(key) + len(value)
+		}
+		return size, true
+	case interface{ Val() map[string]interface{} }:
+		size := 0
+		for key, value := range cmd.Val() {
+			size += len(key) + valueSize(value)
+		}
+		return size, true
+	case interface{ Val() []interface{} }:
+		return valueSize(cmd.Val()), true
+	case interface{ Val() interface{} }:
+		return valueSize(cmd.Val()), true
+	}
+	return 0, false
+}
+
+func valueSize(value interface{}) int {
+	switch value := value.(type) {
+	case string:
+		return len(value)
+	case []byte:
+		return len(value)
+	case []interface{}:
+		size := 0
+		for _, element := range value {
+			size += valueSize(element)
+		}
+		return size
+	case map[interface{}]interface{}:
+		size := 0
+		for key, element := range value {
+			size += valueSize(key) + valueSize(element)
+		}
+		return size
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package rediscmd
+
+import "testing"
+
+// reply is a command replying a value of type T, like the commands of go-redis.
+type reply[T any] struct {
+	val T
+}
+
+func (r reply[T]) Val() T { return r.val }
+
+func TestArgsSize(t *testing.T) {
+	args := []interface{}{"set", []byte("key"), "value", "ex", 3600}
+	if got, want := ArgsSize(args), len("setkeyvalueex3600"); got != want {
+		t.Errorf("ArgsSize(%v) = %d, want %d", args, got, want)
+	}
+}
+
+func TestReplySize(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		cmd  interface{}
+		want int
+		ok   bool
+	}{
+		{"string", reply[string]{"value"}, 5, true},
+		{"strings", reply[[]string]{[]string{"a", "bc"}}, 3, true},
+		{"string map", reply[map[string]string]{map[string]string{"name": "alice"}}, 9, true},
+		{"map", reply[map[string]interface{}]{map[string]interface{}{"name": "alice", "age": int64(42)}}, 12, true},
+		{"slice", reply[[]interface{}]{[]interface{}{"a", nil, []byte("bc")}}, 3, true},
+		{"nested", reply[interface{}]{[]interface{}{"a", []interface{}{"bc"}, map[interface{}]interface{}{"d": "ef"}}}, 6, true},
+		{"integer", reply[int64]{42}, 0, false},
+		{"status", struct{}{}, 0, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ReplySize(tt.cmd)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ReplySize() = %d, %v, want %d, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+//
+// Package redismetrics
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  classifier
+// @Date: 2026/10/17 21:30
+//
+
+package redismetrics
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/feymanlee/monitorit"
+)
+
+// redisError is implemented by the Redis error replies of all go-redis versions.
+type redisError interface {
+	error
+	RedisError()
+}
+
+// ErrorClassifier returns the classifier of go-redis errors given the nil reply and closed client
+// errors of its version, it recognizes the go-redis client errors and Redis error replies and
+// falls back to monitorit.DefaultErrorClassifier.
+func ErrorClassifier(nilErr error, closedErr error) monitorit.ErrorClassifier {
+	return monitorit.ChainErrorClassifiers(
+		monitorit.ErrorClassifierFunc(func(err error) string {
+			return classifyRedisError(err, nilErr, closedErr)
+		}),
+		monitorit.DefaultErrorClassifier(),
+	)
+}
+
+func classifyRedisError(err error, nilErr error, closedErr error) string {
+	switch {
+	case errors.Is(err, nilErr):
+		return monitorit.ErrorClassNotFound
+	case errors.Is(err, closedErr):
+		return monitorit.ErrorClassConnection
+	case err.Error() == "redis: connection pool timeout":
+		return monitorit.ErrorClassTimeout
+	}
+
+	var redisErr redisError
+	if !errors.As(err, &redisErr) {
+		return monitorit.ErrorClassOther
+	}
+	// Error replies start with an upper-case error code, e.g. "WRONGTYPE Operation against a key ...".
+	msg := redisErr.Error()
+	code := msg
+	if index := strings.IndexByte(msg, ' '); index != -1 {
+		code = msg[:index]
+	}
+	// WRONGTYPE is left to other, the command is valid but the key holds another type
+	switch code {
+	case "NOSCRIPT":
+		return monitorit.ErrorClassNotFound
+	case "BUSY":
+		return monitorit.ErrorClassTimeout
+	case "LOADING", "READONLY", "MASTERDOWN", "CLUSTERDOWN", "TRYAGAIN", "MOVED", "ASK":
+		return monitorit.ErrorClassConnection
+	case "ERR":
+		if strings.Contains(msg, "syntax error") || strings.Contains(msg, "wrong number of arguments") ||
+			strings.Contains(msg, "unknown command") {
+			return monitorit.ErrorClassSyntax
+		}
+	}
+	return monitorit.ErrorClassOther
+}
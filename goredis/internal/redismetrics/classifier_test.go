@@ -0,0 +1,51 @@
+package redismetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/feymanlee/monitorit"
+)
+
+// replyError is an error reply of Redis, like the go-redis ones.
+type replyError string
+
+func (e replyError) Error() string { return string(e) }
+func (replyError) RedisError()     {}
+
+func TestErrorClassifier(t *testing.T) {
+	var (
+		nilErr    = errors.New("redis: nil")
+		closedErr = errors.New("redis: client is closed")
+	)
+	classifier := ErrorClassifier(nilErr, closedErr)
+	for _, tt := range []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nilErr, monitorit.ErrorClassNotFound},
+		{"closed", closedErr, monitorit.ErrorClassConnection},
+		{"pool timeout", errors.New("redis: connection pool timeout"), monitorit.ErrorClassTimeout},
+		{"deadline", context.DeadlineExceeded, monitorit.ErrorClassTimeout},
+		{"wrong type", replyError("WRONGTYPE Operation against a key holding the wrong kind of value"), monitorit.ErrorClassOther},
+		{"no script", replyError("NOSCRIPT No matching script. Please use EVAL."), monitorit.ErrorClassNotFound},
+		{"busy", replyError("BUSY Redis is busy running a script."), monitorit.ErrorClassTimeout},
+		{"moved", replyError("MOVED 3999 127.0.0.1:6381"), monitorit.ErrorClassConnection},
+		{"read only", replyError("READONLY You can't write against a read only replica."), monitorit.ErrorClassConnection},
+		{"syntax", replyError("ERR syntax error"), monitorit.ErrorClassSyntax},
+		{"arguments", replyError("ERR wrong number of arguments for 'get' command"), monitorit.ErrorClassSyntax},
+		{"unknown command", replyError("ERR unknown command 'foo'"), monitorit.ErrorClassSyntax},
+		{"wrapped", fmt.Errorf("pipeline: %w", replyError("ERR syntax error")), monitorit.ErrorClassSyntax},
+		{"other reply", replyError("ERR value is not an integer or out of range"), monitorit.ErrorClassOther},
+		{"not a reply", errors.New("ERR syntax error"), monitorit.ErrorClassOther},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifier.Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
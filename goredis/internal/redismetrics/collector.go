@@ -0,0 +1,189 @@
+//
+// Package redismetrics
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  collector
+// @Date: 2026/10/17 21:30
+//
+
+package redismetrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type (
+	// PoolStats are the connection pool stats of a client or node, common to all go-redis versions.
+	PoolStats struct {
+		Hits       uint32
+		Misses     uint32
+		Timeouts   uint32
+		TotalConns uint32
+		IdleConns  uint32
+		StaleConns uint32
+	}
+
+	// Pool is a client whose connection pool stats are collected.
+	Pool interface {
+		PoolStats() PoolStats
+		// ForEachNode calls fn with the address and pool stats of each node of cluster and ring
+		// clients, concurrently, and does nothing for the other clients.
+		ForEachNode(ctx context.Context, fn func(node string, stats PoolStats)) error
+	}
+
+	// StatsCollector is a prometheus.Collector reading the connection pool stats of clients at
+	// scrape time, labeled by their instance name.
+	StatsCollector struct {
+		mu    sync.RWMutex
+		pools map[string]Pool
+
+		hits       *prometheus.Desc
+		misses     *prometheus.Desc
+		timeouts   *prometheus.Desc
+		totalConns *prometheus.Desc
+		idleConns  *prometheus.Desc
+		staleConns *prometheus.Desc
+
+		nodeHits       *prometheus.Desc
+		nodeMisses     *prometheus.Desc
+		nodeTimeouts   *prometheus.Desc
+		nodeTotalConns *prometheus.Desc
+		nodeIdleConns  *prometheus.Desc
+		nodeStaleConns *prometheus.Desc
+
+		nodeStatsErrors *prometheus.CounterVec
+	}
+)
+
+var (
+	statLabelNames     = []string{"instance_name"}
+	nodeStatLabelNames = []string{"instance_name", "node"}
+
+	// nodeStatsTimeout bounds the iteration over the nodes of the clients, which may reload the
+	// cluster slots from the network, so that it doesn't block scrapes.
+	nodeStatsTimeout = 3 * time.Second
+)
+
+// NewStatsCollector creates a collector of pool stats.
+func NewStatsCollector(options *Options) *StatsCollector {
+	newDesc := func(name, help string, labelNames []string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(options.Namespace, options.Subsystem, name), help, labelNames, nil)
+	}
+	return &StatsCollector{
+		pools:      make(map[string]Pool),
+		hits:       newDesc("pool_hits_total", "Number of times a free connection was found in the pool", statLabelNames),
+		misses:     newDesc("pool_misses_total", "Number of times a free connection was not found in the pool", statLabelNames),
+		timeouts:   newDesc("pool_timeouts_total", "Number of times a wait timeout occurred", statLabelNames),
+		totalConns: newDesc("pool_total_conns", "Number of total connections in the pool", statLabelNames),
+		idleConns:  newDesc("pool_idle_conns", "Number of idle connections in the pool", statLabelNames),
+		staleConns: newDesc("pool_stale_conns_total", "Number of stale connections removed from the pool", statLabelNames),
+
+		nodeHits:       newDesc("node_pool_hits_total", "Number of times a free connection was found in the pool of a node", nodeStatLabelNames),
+		nodeMisses:     newDesc("node_pool_misses_total", "Number of times a free connection was not found in the pool of a node", nodeStatLabelNames),
+		nodeTimeouts:   newDesc("node_pool_timeouts_total", "Number of times a wait timeout occurred in the pool of a node", nodeStatLabelNames),
+		nodeTotalConns: newDesc("node_pool_total_conns", "Number of total connections in the pool of a node", nodeStatLabelNames),
+		nodeIdleConns:  newDesc("node_pool_idle_conns", "Number of idle connections in the pool of a node", nodeStatLabelNames),
+		nodeStaleConns: newDesc("node_pool_stale_conns_total", "Number of stale connections removed from the pool of a node", nodeStatLabelNames),
+
+		nodeStatsErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.Namespace,
+			Subsystem: options.Subsystem,
+			Name:      "node_pool_stats_errors_total",
+			Help:      "Number of times the pool stats of the nodes failed to be collected",
+		}, statLabelNames),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *StatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+	ch <- c.nodeHits
+	ch <- c.nodeMisses
+	ch <- c.nodeTimeouts
+	ch <- c.nodeTotalConns
+	ch <- c.nodeIdleConns
+	ch <- c.nodeStaleConns
+	c.nodeStatsErrors.Describe(ch)
+}
+
+// AddPool adds pool to the collected pools under instanceName, replacing any pool previously
+// added with it.
+func (c *StatsCollector) AddPool(instanceName string, pool Pool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pools[instanceName] = pool
+	c.nodeStatsErrors.WithLabelValues(instanceName)
+}
+
+// RemovePool removes the pool added under instanceName, its series are no longer exported.
+func (c *StatsCollector) RemovePool(instanceName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pools, instanceName)
+	c.nodeStatsErrors.DeleteLabelValues(instanceName)
+}
+
+// Collect implements prometheus.Collector. The clients are collected concurrently, their nodes
+// within nodeStatsTimeout.
+func (c *StatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	pools := make(map[string]Pool, len(c.pools))
+	for instanceName, pool := range c.pools {
+		pools[instanceName] = pool
+	}
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), nodeStatsTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	for instanceName, pool := range pools {
+		wg.Add(1)
+		go func(instanceName string, pool Pool) {
+			defer wg.Done()
+			c.collect(ctx, ch, instanceName, pool)
+		}(instanceName, pool)
+	}
+	wg.Wait()
+	c.nodeStatsErrors.Collect(ch)
+}
+
+func (c *StatsCollector) collect(ctx context.Context, ch chan<- prometheus.Metric, instanceName string, pool Pool) {
+	stats := pool.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits), instanceName)
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses), instanceName)
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts), instanceName)
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns), instanceName)
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns), instanceName)
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns), instanceName)
+
+	// The nodes are iterated concurrently, sending to ch is safe.
+	err := pool.ForEachNode(ctx, func(node string, stats PoolStats) {
+		c.collectNode(ch, instanceName, node, stats)
+	})
+	if err != nil {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		// A pool removed meanwhile would be exported again
+		if _, ok := c.pools[instanceName]; ok {
+			c.nodeStatsErrors.WithLabelValues(instanceName).Inc()
+		}
+	}
+}
+
+func (c *StatsCollector) collectNode(ch chan<- prometheus.Metric, instanceName string, node string, stats PoolStats) {
+	ch <- prometheus.MustNewConstMetric(c.nodeHits, prometheus.CounterValue, float64(stats.Hits), instanceName, node)
+	ch <- prometheus.MustNewConstMetric(c.nodeMisses, prometheus.CounterValue, float64(stats.Misses), instanceName, node)
+	ch <- prometheus.MustNewConstMetric(c.nodeTimeouts, prometheus.CounterValue, float64(stats.Timeouts), instanceName, node)
+	ch <- prometheus.MustNewConstMetric(c.nodeTotalConns, prometheus.GaugeValue, float64(stats.TotalConns), instanceName, node)
+	ch <- prometheus.MustNewConstMetric(c.nodeIdleConns, prometheus.GaugeValue, float64(stats.IdleConns), instanceName, node)
+	ch <- prometheus.MustNewConstMetric(c.nodeStaleConns, prometheus.CounterValue, float64(stats.StaleConns), instanceName, node)
+}
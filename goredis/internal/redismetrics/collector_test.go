@@ -0,0 +1,86 @@
+package redismetrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// blockingPool is a pool whose nodes can't be iterated until release is closed or ctx is done,
+// like a cluster client reloading its slots.
+type blockingPool struct {
+	iterating chan<- struct{}
+	release   <-chan struct{}
+}
+
+func (blockingPool) PoolStats() PoolStats {
+	return PoolStats{TotalConns: 1}
+}
+
+func (p blockingPool) ForEachNode(ctx context.Context, fn func(node string, stats PoolStats)) error {
+	if p.iterating != nil {
+		p.iterating <- struct{}{}
+	}
+	select {
+	case <-p.release:
+		fn("127.0.0.1:7000", PoolStats{TotalConns: 1})
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestStatsCollectorNodeStatsTimeout(t *testing.T) {
+	defer func(timeout time.Duration) {
+		nodeStatsTimeout = timeout
+	}(nodeStatsTimeout)
+	nodeStatsTimeout = 10 * time.Millisecond
+
+	collector := NewStatsCollector(DefaultOptions())
+	collector.AddPool("cluster", blockingPool{})
+	collector.AddPool("ring", blockingPool{})
+	done := make(chan int)
+	go func() {
+		done <- testutil.CollectAndCount(collector, "service_component_redis_pool_total_conns")
+	}()
+	select {
+	case got := <-done:
+		if got != 2 {
+			t.Errorf("pool_total_conns series = %d, want 2", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Collect() blocked on the node iteration")
+	}
+	for _, instanceName := range []string{"cluster", "ring"} {
+		if got := testutil.ToFloat64(collector.nodeStatsErrors.WithLabelValues(instanceName)); got != 1 {
+			t.Errorf("node_pool_stats_errors_total{instance_name=%s} = %v, want 1", instanceName, got)
+		}
+	}
+}
+
+func TestStatsCollectorConcurrent(t *testing.T) {
+	collector := NewStatsCollector(DefaultOptions())
+	iterating, release := make(chan struct{}), make(chan struct{})
+	collector.AddPool("cluster", blockingPool{iterating: iterating, release: release})
+	collector.AddPool("ring", blockingPool{iterating: iterating, release: release})
+	done := make(chan int)
+	go func() {
+		done <- testutil.CollectAndCount(collector, "service_component_redis_node_pool_total_conns")
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-iterating:
+		case <-time.After(time.Second):
+			t.Fatal("the nodes of the clients aren't iterated concurrently")
+		}
+	}
+	// The clients can be changed while they're being collected
+	collector.RemovePool("ring")
+	collector.AddPool("ring", blockingPool{})
+	close(release)
+	if got := <-done; got != 2 {
+		t.Errorf("node_pool_total_conns series = %d, want 2", got)
+	}
+}
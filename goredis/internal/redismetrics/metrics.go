@@ -0,0 +1,482 @@
+//
+// Package redismetrics
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  metrics
+// @Date: 2026/10/17 21:30
+//
+
+package redismetrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/feymanlee/monitorit"
+	"github.com/feymanlee/monitorit/goredis/internal/rediscmd"
+)
+
+type (
+	// Cmd is the part of the commands of all go-redis versions the metrics are recorded from,
+	// the replies are read through the Val method of the concrete commands.
+	Cmd interface {
+		Name() string
+		Args() []interface{}
+		Err() error
+	}
+
+	// Metrics records the metrics of the commands, pipelines and Pub/Sub of a client, the hooks
+	// of each go-redis version adapt their commands to it.
+	Metrics struct {
+		options           *Options
+		instanceName      string
+		nilErr            error
+		singleCommands    monitorit.Histogram
+		pipelinedCommands monitorit.Counter
+		singleErrors      monitorit.Counter
+		pipelinedErrors   monitorit.Counter
+		slowCommands      monitorit.Counter
+		pipelineSizes     monitorit.Histogram
+		commandSizes      monitorit.Histogram
+		replySizes        monitorit.Histogram
+		cacheResults      monitorit.Counter
+		blockingCommands  monitorit.Histogram
+		pubsubMessages    monitorit.Counter
+		subscriptions     monitorit.UpDownCounter
+		receiveLatency    monitorit.Histogram
+		amortizedDuration monitorit.Histogram
+		keyPrefixes       *monitorit.LabelLimiter
+		channels          *monitorit.LabelLimiter
+		blocking          map[string]struct{}
+		db                string
+	}
+
+	// pseudoCmd is a command standing for a whole pipeline.
+	pseudoCmd string
+)
+
+var (
+	commandLabelNames = []string{"instance_name", "command"}
+	pubsubLabelNames  = []string{"instance_name"}
+	messageLabelNames = []string{"instance_name", "channel"}
+)
+
+// NewMetrics creates the metrics of the client called instanceName with backend, nilErr is the
+// nil reply error of the go-redis version, which isn't a failure.
+func NewMetrics(instanceName string, options *Options, backend monitorit.Backend, nilErr error) (*Metrics, error) {
+	var keyPrefixes *monitorit.LabelLimiter
+	labelNames := commandLabelNames
+	if options.DBLabel {
+		labelNames = append(labelNames[:len(labelNames):len(labelNames)], "db")
+	}
+	if options.KeyPrefixLabel {
+		if options.MaxKeyPrefixes <= 0 {
+			return nil, fmt.Errorf("redis: WithKeyPrefixLabel needs a positive max number of prefixes, got %d", options.MaxKeyPrefixes)
+		}
+		labelNames = append(labelNames[:len(labelNames):len(labelNames)], "key_prefix")
+		keyPrefixes = monitorit.NewLabelLimiter(options.MaxKeyPrefixes)
+	}
+	errorLabelNames := append(labelNames[:len(labelNames):len(labelNames)], "error")
+	resultLabelNames := append(labelNames[:len(labelNames):len(labelNames)], "result")
+
+	singleCommands, err := backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "single_commands",
+		OTelName:   "db.client.operation.duration",
+		Help:       "Histogram of single Redis commands",
+		Unit:       "s",
+		LabelNames: labelNames,
+		Buckets:    options.DurationBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pipelinedCommands, err := backend.NewCounter(monitorit.MetricOpts{
+		Name:       "pipelined_commands",
+		OTelName:   "db.client.pipelined_operations",
+		Help:       "Number of pipelined Redis commands",
+		Unit:       "{operation}",
+		LabelNames: labelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	singleErrors, err := backend.NewCounter(monitorit.MetricOpts{
+		Name:       "single_errors",
+		OTelName:   "db.client.operation.errors",
+		Help:       "Number of single Redis commands that have failed",
+		Unit:       "{error}",
+		LabelNames: errorLabelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pipelinedErrors, err := backend.NewCounter(monitorit.MetricOpts{
+		Name:       "pipelined_errors",
+		OTelName:   "db.client.pipelined_operation.errors",
+		Help:       "Number of pipelined Redis commands that have failed",
+		Unit:       "{error}",
+		LabelNames: errorLabelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slowCommands, err := backend.NewCounter(monitorit.MetricOpts{
+		Name:       "slow_queries_total",
+		OTelName:   "db.client.operation.slow",
+		Help:       "Number of Redis commands slower than the slow threshold",
+		Unit:       "{operation}",
+		LabelNames: labelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pipelineSizes, err := backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "pipeline_size",
+		OTelName:   "db.client.pipeline.size",
+		Help:       "Histogram of the number of commands in Redis pipelines",
+		Unit:       "{operation}",
+		LabelNames: labelNames,
+		Buckets:    options.PipelineSizeBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var commandSizes, replySizes monitorit.Histogram
+	if options.PayloadSizes {
+		commandSizes, err = backend.NewHistogram(monitorit.MetricOpts{
+			Name:       "command_size_bytes",
+			OTelName:   "db.client.request.size",
+			Help:       "Histogram of the size of the arguments of Redis commands",
+			Unit:       "By",
+			LabelNames: labelNames,
+			Buckets:    options.SizeBuckets,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		replySizes, err = backend.NewHistogram(monitorit.MetricOpts{
+			Name:       "reply_size_bytes",
+			OTelName:   "db.client.response.size",
+			Help:       "Histogram of the size of the string, slice and map replies of Redis commands",
+			Unit:       "By",
+			LabelNames: labelNames,
+			Buckets:    options.SizeBuckets,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var cacheResults monitorit.Counter
+	if options.CacheResults {
+		cacheResults, err = backend.NewCounter(monitorit.MetricOpts{
+			Name:       "cache_results_total",
+			OTelName:   "db.client.cache.results",
+			Help:       "Number of hits and misses of Redis read commands",
+			Unit:       "{result}",
+			LabelNames: resultLabelNames,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var blockingCommands monitorit.Histogram
+	if !options.ExcludeBlocking {
+		blockingCommands, err = backend.NewHistogram(monitorit.MetricOpts{
+			Name:       "blocking_commands",
+			OTelName:   "db.client.blocking_operation.duration",
+			Help:       "Histogram of blocking Redis commands, like BLPOP or XREAD BLOCK",
+			Unit:       "s",
+			LabelNames: labelNames,
+			Buckets:    options.BlockingBuckets,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	blocking := make(map[string]struct{}, len(options.BlockingCommands))
+	for _, command := range options.BlockingCommands {
+		blocking[strings.ToLower(command)] = struct{}{}
+	}
+
+	pubsubMessages, err := backend.NewCounter(monitorit.MetricOpts{
+		Name:       "pubsub_messages_total",
+		OTelName:   "messaging.client.consumed.messages",
+		Help:       "Number of Pub/Sub messages received per channel or pattern",
+		Unit:       "{message}",
+		LabelNames: messageLabelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := backend.NewUpDownCounter(monitorit.MetricOpts{
+		Name:       "pubsub_subscriptions",
+		OTelName:   "messaging.client.subscriptions",
+		Help:       "Number of Pub/Sub channels and patterns subscribed to",
+		Unit:       "{subscription}",
+		LabelNames: pubsubLabelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	receiveLatency, err := backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "pubsub_receive_latency_sec",
+		OTelName:   "messaging.process.duration",
+		Help:       "Histogram of the time Pub/Sub receive loops take to get back to receiving after a message",
+		Unit:       "s",
+		LabelNames: pubsubLabelNames,
+		Buckets:    options.DurationBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var amortizedDuration monitorit.Histogram
+	if options.AmortizedPipelineDuration {
+		amortizedDuration, err = backend.NewHistogram(monitorit.MetricOpts{
+			Name:       "pipelined_command_duration_sec",
+			OTelName:   "db.client.pipelined_operation.duration",
+			Help:       "Histogram of pipelined Redis commands, amortized over their pipeline",
+			Unit:       "s",
+			LabelNames: labelNames,
+			Buckets:    options.DurationBuckets,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Metrics{
+		options:           options,
+		instanceName:      instanceName,
+		nilErr:            nilErr,
+		singleCommands:    singleCommands,
+		pipelinedCommands: pipelinedCommands,
+		singleErrors:      singleErrors,
+		pipelinedErrors:   pipelinedErrors,
+		slowCommands:      slowCommands,
+		pipelineSizes:     pipelineSizes,
+		commandSizes:      commandSizes,
+		replySizes:        replySizes,
+		cacheResults:      cacheResults,
+		blockingCommands:  blockingCommands,
+		pubsubMessages:    pubsubMessages,
+		subscriptions:     subscriptions,
+		receiveLatency:    receiveLatency,
+		amortizedDuration: amortizedDuration,
+		keyPrefixes:       keyPrefixes,
+		channels:          monitorit.NewLabelLimiter(options.MaxChannels),
+		blocking:          blocking,
+		db:                strconv.Itoa(options.DB),
+	}, nil
+}
+
+// RecordCommand records a single command which has taken elapsed and failed with err, if any.
+func (m *Metrics) RecordCommand(ctx context.Context, cmd Cmd, err error, elapsed time.Duration) {
+	labelValues := m.labelValues(cmd)
+	m.recordCommand(ctx, cmd, labelValues, err, elapsed)
+	m.recordSizes(ctx, cmd, labelValues, err)
+	m.recordCacheResults(ctx, cmd, labelValues, err)
+}
+
+// RecordPipeline records a pipeline which has taken elapsed, err being the error returned by the
+// hook chain. The commands of a transaction are wrapped by MULTI and EXEC.
+func (m *Metrics) RecordPipeline(ctx context.Context, cmds []Cmd, err error, elapsed time.Duration) {
+	commands, tx := txCommands(cmds)
+	var pipeline Cmd = pseudoCmd("pipeline")
+	if tx {
+		pipeline = pseudoCmd("tx_pipeline")
+	}
+	pipelineLabelValues := m.labelValues(pipeline)
+	m.recordCommand(ctx, pipeline, pipelineLabelValues, nil, elapsed)
+	m.pipelineSizes.Observe(ctx, float64(len(commands)), pipelineLabelValues...)
+
+	unsent := unsentErr(cmds, err)
+	for _, cmd := range commands {
+		labelValues := m.labelValues(cmd)
+		m.pipelinedCommands.Add(ctx, 1, labelValues...)
+
+		cmdErr := CommandErr(cmd, unsent)
+		if m.isActualErr(cmdErr) {
+			m.pipelinedErrors.Add(ctx, 1, append(labelValues, m.options.ErrorClassifier.Classify(cmdErr))...)
+		}
+		m.recordSizes(ctx, cmd, labelValues, cmdErr)
+		m.recordCacheResults(ctx, cmd, labelValues, cmdErr)
+	}
+
+	if m.amortizedDuration != nil {
+		m.recordAmortizedDurations(ctx, commands, elapsed)
+	}
+}
+
+func (m *Metrics) recordCommand(ctx context.Context, cmd Cmd, labelValues []string, err error, elapsed time.Duration) {
+	if m.isBlocking(cmd) {
+		// Blocking commands are expected to be long, they're neither single nor slow commands
+		if m.blockingCommands != nil {
+			m.blockingCommands.Observe(ctx, elapsed.Seconds(), labelValues...)
+		}
+	} else {
+		m.singleCommands.Observe(ctx, elapsed.Seconds(), labelValues...)
+		if m.options.SlowThreshold > 0 && elapsed >= m.options.SlowThreshold {
+			m.recordSlowCommand(ctx, cmd, err, labelValues, elapsed)
+		}
+	}
+
+	if m.isActualErr(err) {
+		m.singleErrors.Add(ctx, 1, append(labelValues, m.options.ErrorClassifier.Classify(err))...)
+	}
+}
+
+// recordSizes records the size of the arguments of cmd, and of its reply if it has succeeded.
+func (m *Metrics) recordSizes(ctx context.Context, cmd Cmd, labelValues []string, err error) {
+	if m.commandSizes == nil {
+		return
+	}
+	m.commandSizes.Observe(ctx, float64(rediscmd.ArgsSize(cmd.Args())), labelValues...)
+	if err != nil {
+		return
+	}
+	if size, ok := rediscmd.ReplySize(cmd); ok {
+		m.replySizes.Observe(ctx, float64(size), labelValues...)
+	}
+}
+
+// recordCacheResults counts the hits and misses of cmd if it's a read command.
+func (m *Metrics) recordCacheResults(ctx context.Context, cmd Cmd, labelValues []string, err error) {
+	if m.cacheResults == nil || m.isActualErr(err) {
+		return
+	}
+	hits, misses, ok := rediscmd.CacheResults(cmd.Name(), cmd, err != nil)
+	if !ok {
+		return
+	}
+	if hits > 0 {
+		m.cacheResults.Add(ctx, float64(hits), append(labelValues, "hit")...)
+	}
+	if misses > 0 {
+		m.cacheResults.Add(ctx, float64(misses), append(labelValues, "miss")...)
+	}
+}
+
+// recordAmortizedDurations records the duration of each command of a pipeline as the duration
+// of the pipeline divided by its number of commands.
+func (m *Metrics) recordAmortizedDurations(ctx context.Context, cmds []Cmd, elapsed time.Duration) {
+	if len(cmds) == 0 {
+		return
+	}
+	amortized := elapsed.Seconds() / float64(len(cmds))
+	for _, cmd := range cmds {
+		m.amortizedDuration.Observe(ctx, amortized, m.labelValues(cmd)...)
+	}
+}
+
+func (m *Metrics) recordSlowCommand(ctx context.Context, cmd Cmd, err error, labelValues []string, elapsed time.Duration) {
+	m.slowCommands.Add(ctx, 1, labelValues...)
+	if m.options.SlowQuerySink == nil {
+		return
+	}
+	query := monitorit.SlowQuery{
+		Name:      m.instanceName,
+		Command:   cmd.Name(),
+		Statement: redactCommand(cmd),
+		Duration:  elapsed,
+		Caller:    monitorit.Caller("github.com/go-redis/redis", "github.com/redis/go-redis"),
+	}
+	if m.isActualErr(err) {
+		query.Err = err
+	}
+	m.options.SlowQuerySink.Record(ctx, query)
+}
+
+// isBlocking returns whether cmd is a blocking command.
+func (m *Metrics) isBlocking(cmd Cmd) bool {
+	if _, ok := m.blocking[cmd.Name()]; !ok {
+		return false
+	}
+	return rediscmd.Blocks(cmd.Name(), cmd.Args())
+}
+
+// labelValues returns the values of the command labels of cmd.
+func (m *Metrics) labelValues(cmd Cmd) []string {
+	labelValues := []string{m.instanceName, cmd.Name()}
+	if m.options.DBLabel {
+		labelValues = append(labelValues, m.db)
+	}
+	if m.keyPrefixes != nil {
+		var keyPrefix string
+		if key, ok := rediscmd.FirstKey(cmd.Name(), cmd.Args()); ok {
+			keyPrefix = m.keyPrefixes.Value(rediscmd.KeyPrefix(key, m.options.KeyPrefixDelimiter, m.options.KeyPrefixDepth))
+		}
+		labelValues = append(labelValues, keyPrefix)
+	}
+	return labelValues
+}
+
+// isActualErr returns whether err is a failure, i.e. neither nil nor a nil reply.
+func (m *Metrics) isActualErr(err error) bool {
+	return err != nil && err != m.nilErr
+}
+
+func (cmd pseudoCmd) Name() string {
+	return string(cmd)
+}
+
+func (cmd pseudoCmd) Args() []interface{} {
+	return []interface{}{string(cmd)}
+}
+
+func (cmd pseudoCmd) Err() error {
+	return nil
+}
+
+// CommandErr returns the error of cmd, or err returned by the hook chain if cmd has none yet,
+// as go-redis v9 only sets the error of a failed command once the hooks have returned.
+func CommandErr(cmd Cmd, err error) error {
+	if cmd.Err() != nil {
+		return cmd.Err()
+	}
+	return err
+}
+
+// unsentErr returns err if none of cmds has an error, i.e. the pipeline has failed before
+// they were sent, nil otherwise as err is then the error of one of them.
+func unsentErr(cmds []Cmd, err error) error {
+	for _, cmd := range cmds {
+		if cmd.Err() != nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// redactCommand returns the command name followed by a placeholder for each argument.
+func redactCommand(cmd Cmd) string {
+	var b strings.Builder
+	b.WriteString(cmd.Name())
+	for i := 1; i < len(cmd.Args()); i++ {
+		b.WriteString(" ?")
+	}
+	return b.String()
+}
+
+// txCommands returns the commands of a pipeline without the MULTI and EXEC wrapping them if
+// it's a transaction, and whether it is.
+func txCommands(cmds []Cmd) ([]Cmd, bool) {
+	if len(cmds) >= 2 && cmds[0].Name() == "multi" && cmds[len(cmds)-1].Name() == "exec" {
+		return cmds[1 : len(cmds)-1], true
+	}
+	return cmds, false
+}
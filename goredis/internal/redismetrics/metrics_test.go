@@ -0,0 +1,384 @@
+package redismetrics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/feymanlee/monitorit"
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics/metricstest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// command is a command of any go-redis version.
+type command struct {
+	args []interface{}
+	val  interface{}
+	err  error
+}
+
+func (cmd command) Name() string        { return strings.ToLower(cmd.args[0].(string)) }
+func (cmd command) Args() []interface{} { return cmd.args }
+func (cmd command) Err() error          { return cmd.err }
+func (cmd command) Val() interface{}    { return cmd.val }
+
+var errNil = errors.New("redis: nil")
+
+func newMetrics(t *testing.T, opts ...Option) (*Metrics, *prometheus.Registry) {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	options := DefaultOptions()
+	options.Registerer = registry
+	options.ErrorClassifier = ErrorClassifier(errNil, errors.New("redis: client is closed"))
+	options.Merge(opts...)
+	m, err := NewMetrics("test", options, options.Backend("test"), errNil)
+	if err != nil {
+		t.Fatalf("NewMetrics() error = %v", err)
+	}
+	return m, registry
+}
+
+// withKeyPrefixLabel labels the commands by the prefix of their key, as the WithKeyPrefixLabel
+// of each go-redis version.
+func withKeyPrefixLabel(delimiter string, depth int, maxPrefixes int) Option {
+	return func(options *Options) {
+		options.KeyPrefixLabel = true
+		options.KeyPrefixDelimiter = delimiter
+		options.KeyPrefixDepth = depth
+		options.MaxKeyPrefixes = maxPrefixes
+	}
+}
+
+// histogramSum returns the sum of the observations of the histogram called name for command.
+func histogramSum(t *testing.T, registry *prometheus.Registry, name string, command string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == "command" && pair.GetValue() == command {
+					return metric.GetHistogram().GetSampleSum()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestRecordCommand(t *testing.T) {
+	m, registry := newMetrics(t)
+	ctx := context.Background()
+	for _, cmd := range []command{
+		{args: []interface{}{"set", "key", "value"}, val: "OK"},
+		{args: []interface{}{"get", "key"}, val: "value"},
+		{args: []interface{}{"get", "missing"}, err: errNil},
+		{args: []interface{}{"get", "key"}, err: replyError("LOADING Redis is loading the dataset in memory")},
+		{args: []interface{}{"mget", "key", "missing", "key"}, val: []interface{}{"value", nil, "value"}},
+		{args: []interface{}{"lpush", "key", "value"}, err: replyError("WRONGTYPE Operation against a key holding the wrong kind of value")},
+	} {
+		m.RecordCommand(ctx, cmd, cmd.err, time.Millisecond)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"service_component_redis_single_commands", map[string]string{"command": "get"}, 3},
+		{"service_component_redis_single_commands", map[string]string{"command": "set"}, 1},
+		{"service_component_redis_single_errors", map[string]string{"command": "get", "error": monitorit.ErrorClassNotFound}, 0},
+		{"service_component_redis_single_errors", map[string]string{"command": "get", "error": monitorit.ErrorClassConnection}, 1},
+		{"service_component_redis_single_errors", map[string]string{"command": "lpush", "error": monitorit.ErrorClassOther}, 1},
+		// The failed GET is neither a hit nor a miss
+		{"service_component_redis_cache_results_total", map[string]string{"command": "get", "result": "hit"}, 1},
+		{"service_component_redis_cache_results_total", map[string]string{"command": "get", "result": "miss"}, 1},
+		{"service_component_redis_cache_results_total", map[string]string{"command": "mget", "result": "hit"}, 2},
+		{"service_component_redis_cache_results_total", map[string]string{"command": "mget", "result": "miss"}, 1},
+	} {
+		if got := metricstest.Value(t, registry, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestRecordPipeline(t *testing.T) {
+	m, registry := newMetrics(t)
+	ctx := context.Background()
+	m.RecordPipeline(ctx, []Cmd{
+		command{args: []interface{}{"set", "key", "value"}, val: "OK"},
+		command{args: []interface{}{"get", "key"}, val: "value"},
+		command{args: []interface{}{"get", "missing"}, err: errNil},
+	}, errNil, time.Millisecond)
+	m.RecordPipeline(ctx, []Cmd{
+		command{args: []interface{}{"multi"}, val: "OK"},
+		command{args: []interface{}{"incr", "counter"}, val: int64(1)},
+		command{args: []interface{}{"incr", "counter"}, val: int64(2)},
+		command{args: []interface{}{"exec"}, val: []interface{}{int64(1), int64(2)}},
+	}, nil, time.Millisecond)
+
+	for _, tt := range []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"service_component_redis_single_commands", map[string]string{"command": "pipeline"}, 1},
+		{"service_component_redis_single_commands", map[string]string{"command": "tx_pipeline"}, 1},
+		{"service_component_redis_pipeline_size", map[string]string{"command": "pipeline"}, 1},
+		{"service_component_redis_pipeline_size", map[string]string{"command": "tx_pipeline"}, 1},
+		{"service_component_redis_pipelined_commands", map[string]string{"command": "get"}, 2},
+		{"service_component_redis_pipelined_commands", map[string]string{"command": "incr"}, 2},
+		{"service_component_redis_pipelined_commands", map[string]string{"command": "multi"}, 0},
+		{"service_component_redis_pipelined_commands", map[string]string{"command": "exec"}, 0},
+		{"service_component_redis_pipelined_errors", map[string]string{"command": "get"}, 0},
+		{"service_component_redis_cache_results_total", map[string]string{"command": "get", "result": "miss"}, 1},
+	} {
+		if got := metricstest.Value(t, registry, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+	// MULTI and EXEC aren't part of the size of the transaction
+	if got := histogramSum(t, registry, "service_component_redis_pipeline_size", "tx_pipeline"); got != 2 {
+		t.Errorf("pipeline_size{command=tx_pipeline} sum = %v, want 2", got)
+	}
+}
+
+func TestRecordBlockingCommand(t *testing.T) {
+	m, registry := newMetrics(t)
+	ctx := context.Background()
+	for _, args := range [][]interface{}{
+		{"blpop", "queue", 1},
+		// XREAD only blocks with BLOCK
+		{"xread", "streams", "stream", "0"},
+	} {
+		m.RecordCommand(ctx, command{args: args}, nil, time.Millisecond)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		command string
+		want    float64
+	}{
+		{"service_component_redis_blocking_commands", "blpop", 1},
+		{"service_component_redis_single_commands", "blpop", 0},
+		{"service_component_redis_blocking_commands", "xread", 0},
+		{"service_component_redis_single_commands", "xread", 1},
+	} {
+		if got := metricstest.Value(t, registry, tt.name, map[string]string{"command": tt.command}); got != tt.want {
+			t.Errorf("%s{command=%s} = %v, want %v", tt.name, tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestPubSub(t *testing.T) {
+	m, registry := newMetrics(t)
+	ctx := context.Background()
+	ps := m.PubSub()
+	ps.Subscribed(ctx, 2)
+	ps.Received(ctx, "events", "")
+	ps.Received(ctx, "events.1", "events.*")
+
+	ch := make(chan string)
+	delivered := make(chan bool)
+	go func() {
+		for _, msg := range []string{"hello", "world"} {
+			delivered <- Deliver(ctx, ps, ch, msg)
+		}
+	}()
+	// The second message waits for the application busy with the first one
+	<-ch
+	<-delivered
+	time.Sleep(10 * time.Millisecond)
+	<-ch
+	<-delivered
+
+	for _, tt := range []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"service_component_redis_pubsub_messages_total", map[string]string{"channel": "events"}, 1},
+		{"service_component_redis_pubsub_messages_total", map[string]string{"channel": "events.*"}, 1},
+		{"service_component_redis_pubsub_subscriptions", nil, 2},
+		{"service_component_redis_pubsub_receive_latency_sec", nil, 1},
+	} {
+		if got := metricstest.Value(t, registry, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+
+	ps.Close()
+	if got := metricstest.Value(t, registry, "service_component_redis_pubsub_subscriptions", nil); got != 0 {
+		t.Errorf("pubsub_subscriptions after Close = %v, want 0", got)
+	}
+	// Once closed, a message the application doesn't take isn't delivered
+	if Deliver(ctx, ps, ch, "dropped") {
+		t.Error("Deliver() after Close = true, want false")
+	}
+}
+
+func TestKeyPrefixLabel(t *testing.T) {
+	m, registry := newMetrics(t, withKeyPrefixLabel(":", 1, 2))
+	ctx := context.Background()
+	for _, args := range [][]interface{}{
+		{"get", "session:1"},
+		{"get", "session:2"},
+		{"lmpop", 2, "queue:1", "queue:2", "left"},
+		// Over the max prefixes
+		{"get", "user:1"},
+		{"ping"},
+	} {
+		m.RecordCommand(ctx, command{args: args}, nil, time.Millisecond)
+	}
+
+	want := []string{"", "other", "queue:*", "session:*"}
+	if got := metricstest.LabelValues(t, registry, "service_component_redis_single_commands", "key_prefix"); !reflect.DeepEqual(got, want) {
+		t.Errorf("key_prefix values = %v, want %v", got, want)
+	}
+}
+
+func TestKeyPrefixLabelMaxPrefixes(t *testing.T) {
+	for _, max := range []int{0, -1} {
+		options := DefaultOptions()
+		options.Registerer = prometheus.NewRegistry()
+		options.Merge(withKeyPrefixLabel(":", 1, max))
+		if _, err := NewMetrics("test", options, options.Backend("test"), errNil); err == nil {
+			t.Errorf("NewMetrics() with %d max prefixes succeeded", max)
+		}
+	}
+}
+
+func TestDBLabel(t *testing.T) {
+	m, registry := newMetrics(t, func(options *Options) {
+		options.DBLabel = true
+		options.DB = 3
+	})
+	m.RecordCommand(context.Background(), command{args: []interface{}{"get", "key"}}, nil, time.Millisecond)
+
+	if got, want := metricstest.LabelValues(t, registry, "service_component_redis_single_commands", "db"), []string{"3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("db values = %v, want %v", got, want)
+	}
+}
+
+func TestNativeHistograms(t *testing.T) {
+	m, registry := newMetrics(t, func(options *Options) {
+		options.NativeHistogramBucketFactor = 1.1
+		options.NativeHistogramMaxBuckets = 100
+	})
+	m.RecordCommand(context.Background(), command{args: []interface{}{"get", "key"}, val: "value"}, nil, time.Millisecond)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	var histograms int
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if h := metric.GetHistogram(); h != nil {
+				histograms++
+				if h.Schema == nil {
+					t.Errorf("%s isn't a native histogram", family.GetName())
+				}
+			}
+		}
+	}
+	if histograms == 0 {
+		t.Error("no histogram was gathered")
+	}
+}
+
+func TestAmortizedPipelineDuration(t *testing.T) {
+	pipeline := []Cmd{
+		command{args: []interface{}{"multi"}, val: "OK"},
+		command{args: []interface{}{"incr", "counter"}, val: int64(1)},
+		command{args: []interface{}{"get", "key"}, val: "value"},
+		command{args: []interface{}{"incr", "counter"}, val: int64(2)},
+		command{args: []interface{}{"exec"}, val: []interface{}{int64(1), "value", int64(2)}},
+	}
+	for _, tt := range []struct {
+		name      string
+		amortized bool
+		want      []string
+	}{
+		{"disabled", false, nil},
+		// MULTI and EXEC aren't part of the transaction
+		{"enabled", true, []string{"get", "incr"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			m, registry := newMetrics(t, func(options *Options) {
+				options.AmortizedPipelineDuration = tt.amortized
+			})
+			m.RecordPipeline(context.Background(), pipeline, nil, 30*time.Millisecond)
+
+			if got := metricstest.LabelValues(t, registry, "service_component_redis_pipelined_command_duration_sec", "command"); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("pipelined_command_duration_sec commands = %v, want %v", got, tt.want)
+			}
+			if !tt.amortized {
+				return
+			}
+			for _, want := range []struct {
+				name  string
+				count float64
+			}{
+				{"get", 1},
+				{"incr", 2},
+			} {
+				labels := map[string]string{"command": want.name}
+				if got := metricstest.Value(t, registry, "service_component_redis_pipelined_command_duration_sec", labels); got != want.count {
+					t.Errorf("pipelined_command_duration_sec{command=%s} samples = %v, want %v", want.name, got, want.count)
+				}
+				// Each command is given a third of the pipeline
+				sum := histogramSum(t, registry, "service_component_redis_pipelined_command_duration_sec", want.name)
+				if wantSum := want.count * .01; math.Abs(sum-wantSum) > 1e-9 {
+					t.Errorf("pipelined_command_duration_sec{command=%s} sum = %v, want %v", want.name, sum, wantSum)
+				}
+			}
+		})
+	}
+}
+
+func TestPayloadSizes(t *testing.T) {
+	m, registry := newMetrics(t, func(options *Options) {
+		options.PayloadSizes = true
+		options.SizeBuckets = []float64{10}
+	})
+	ctx := context.Background()
+	m.RecordCommand(ctx, command{args: []interface{}{"set", "key", "value"}, val: "OK"}, nil, time.Millisecond)
+	m.RecordCommand(ctx, command{args: []interface{}{"get", "key"}, val: "value"}, nil, time.Millisecond)
+	// Failed commands have no reply
+	m.RecordCommand(ctx, command{args: []interface{}{"get", "missing"}, err: errNil}, errNil, time.Millisecond)
+	m.RecordPipeline(ctx, []Cmd{
+		command{args: []interface{}{"mget", "key", "missing"}, val: []interface{}{"value", nil}},
+	}, nil, time.Millisecond)
+
+	for _, tt := range []struct {
+		name    string
+		command string
+		count   float64
+		sum     float64
+	}{
+		{"service_component_redis_command_size_bytes", "set", 1, 11},
+		{"service_component_redis_command_size_bytes", "get", 2, 16},
+		{"service_component_redis_command_size_bytes", "mget", 1, 14},
+		{"service_component_redis_reply_size_bytes", "set", 1, 2},
+		{"service_component_redis_reply_size_bytes", "get", 1, 5},
+		{"service_component_redis_reply_size_bytes", "mget", 1, 5},
+	} {
+		labels := map[string]string{"command": tt.command}
+		if got := metricstest.Value(t, registry, tt.name, labels); got != tt.count {
+			t.Errorf("%s{command=%s} samples = %v, want %v", tt.name, tt.command, got, tt.count)
+		}
+		if got := histogramSum(t, registry, tt.name, tt.command); got != tt.sum {
+			t.Errorf("%s{command=%s} sum = %v, want %v", tt.name, tt.command, got, tt.sum)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+//
+// Package metricstest
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  metricstest
+// @Date: 2026/10/17 23:40
+//
+
+package metricstest
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Value returns the value of the counter or gauge, or the sample count of the histogram, called
+// name whose series has labels, 0 if there's none.
+func Value(t testing.TB, gatherer prometheus.Gatherer, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if value, ok := labels[pair.GetName()]; ok && value != pair.GetValue() {
+					continue metrics
+				}
+			}
+			switch {
+			case metric.GetCounter() != nil:
+				return metric.GetCounter().GetValue()
+			case metric.GetGauge() != nil:
+				return metric.GetGauge().GetValue()
+			case metric.GetHistogram() != nil:
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+// LabelValues returns the sorted values of the label of the series of the metric called name.
+func LabelValues(t testing.TB, gatherer prometheus.Gatherer, name string, label string) []string {
+	t.Helper()
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	var values []string
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == label {
+					values = append(values, pair.GetValue())
+				}
+			}
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// WaitSubscribed waits for server to have a subscriber to channel, so that the messages
+// published next are received.
+func WaitSubscribed(server *miniredis.Miniredis, channel string) {
+	for server.PubSubNumSub(channel)[channel] == 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
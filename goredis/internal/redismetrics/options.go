@@ -0,0 +1,103 @@
+//
+// Package redismetrics
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  options
+// @Date: 2026/10/17 21:30
+//
+
+package redismetrics
+
+import (
+	"time"
+
+	"github.com/feymanlee/monitorit"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type (
+	// Options represents options to customize the exported metrics.
+	Options struct {
+		Namespace       string
+		Subsystem       string
+		DurationBuckets []float64
+		StatInterval    time.Duration
+		Registerer      prometheus.Registerer
+		ErrorClassifier monitorit.ErrorClassifier
+		SlowThreshold   time.Duration
+		SlowQuerySink   monitorit.SlowQuerySink
+
+		NativeHistogramBucketFactor float64
+		NativeHistogramMaxBuckets   uint32
+		TraceIDExtractor            monitorit.TraceIDExtractor
+		MeterProvider               metric.MeterProvider
+
+		KeyPrefixLabel     bool
+		KeyPrefixDelimiter string
+		KeyPrefixDepth     int
+		MaxKeyPrefixes     int
+		DBLabel            bool
+		DB                 int
+
+		PipelineSizeBuckets       []float64
+		AmortizedPipelineDuration bool
+		PayloadSizes              bool
+		SizeBuckets               []float64
+		CacheResults              bool
+
+		BlockingCommands []string
+		BlockingBuckets  []float64
+		ExcludeBlocking  bool
+		MaxChannels      int
+	}
+
+	Option func(*Options)
+)
+
+// DefaultOptions returns the default options, but the error classifier which depends on the
+// version of go-redis.
+func DefaultOptions() *Options {
+	return &Options{
+		Namespace:       "service_component",
+		Subsystem:       "redis",
+		DurationBuckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1},
+		StatInterval:    time.Second * 10,
+		Registerer:      prometheus.DefaultRegisterer,
+
+		TraceIDExtractor: monitorit.OpenTelemetryTraceID,
+
+		PipelineSizeBuckets: []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+		SizeBuckets:         prometheus.ExponentialBuckets(64, 4, 10),
+		CacheResults:        true,
+
+		BlockingCommands: []string{
+			"blpop", "brpop", "brpoplpush", "blmove", "blmpop", "bzpopmin", "bzpopmax", "bzmpop",
+			"xread", "xreadgroup", "wait", "waitaof", "subscribe", "psubscribe", "ssubscribe",
+		},
+		BlockingBuckets: []float64{.01, .05, .1, .5, 1, 2.5, 5, 10, 30, 60},
+		MaxChannels:     100,
+	}
+}
+
+func (options *Options) Merge(opts ...Option) {
+	for _, opt := range opts {
+		opt(options)
+	}
+}
+
+// Backend returns the backend the metrics are recorded to, scope being the OpenTelemetry
+// instrumentation scope.
+func (options *Options) Backend(scope string) monitorit.Backend {
+	if options.MeterProvider != nil {
+		return monitorit.NewOpenTelemetryBackend(options.MeterProvider, scope)
+	}
+	return monitorit.NewPrometheusBackend(monitorit.PrometheusOptions{
+		Namespace:                   options.Namespace,
+		Subsystem:                   options.Subsystem,
+		Registerer:                  options.Registerer,
+		NativeHistogramBucketFactor: options.NativeHistogramBucketFactor,
+		NativeHistogramMaxBuckets:   options.NativeHistogramMaxBuckets,
+		TraceIDExtractor:            options.TraceIDExtractor,
+	})
+}
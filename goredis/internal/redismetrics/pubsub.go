@@ -0,0 +1,100 @@
+//
+// Package redismetrics
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  pubsub
+// @Date: 2026/10/17 21:30
+//
+
+package redismetrics
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PubSub records the messages, subscriptions and receive loop latency of a Pub/Sub, the
+// wrappers of each go-redis version report what they receive to it.
+type PubSub struct {
+	metrics *Metrics
+
+	mu           sync.Mutex
+	count        int
+	lastReceived time.Time
+	closeOnce    sync.Once
+	done         chan struct{}
+}
+
+// PubSub returns a recorder of the metrics of a new Pub/Sub.
+func (m *Metrics) PubSub() *PubSub {
+	return &PubSub{
+		metrics: m,
+		done:    make(chan struct{}),
+	}
+}
+
+// Receiving records the time the application has taken to get back to receiving since the last
+// message was returned to it.
+func (ps *PubSub) Receiving(ctx context.Context) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if !ps.lastReceived.IsZero() {
+		ps.metrics.receiveLatency.Observe(ctx, time.Since(ps.lastReceived).Seconds(), ps.metrics.instanceName)
+	}
+}
+
+// Returned marks a message as returned to the application.
+func (ps *PubSub) Returned() {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.lastReceived = time.Now()
+}
+
+// Subscribed updates the number of subscriptions with the count confirmed by Redis.
+func (ps *PubSub) Subscribed(ctx context.Context, count int) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.metrics.subscriptions.Add(ctx, float64(count-ps.count), ps.metrics.instanceName)
+	ps.count = count
+}
+
+// Received counts a message received on channel, labeled by pattern if it matched one.
+func (ps *PubSub) Received(ctx context.Context, channel string, pattern string) {
+	if pattern != "" {
+		channel = pattern
+	}
+	ps.metrics.pubsubMessages.Add(ctx, 1, ps.metrics.instanceName, ps.metrics.channels.Value(channel))
+}
+
+// Close stops counting the subscriptions of the Pub/Sub.
+func (ps *PubSub) Close() {
+	ps.closeOnce.Do(func() {
+		close(ps.done)
+	})
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.metrics.subscriptions.Add(context.Background(), float64(-ps.count), ps.metrics.instanceName)
+	ps.count = 0
+}
+
+// Deliver sends msg to the application through ch, it returns false if the Pub/Sub is closed
+// before the application takes it.
+//
+// The time the application has taken to get back to receiving is only recorded when it's still
+// busy with the previous message, as it isn't seen getting back to receiving if it's already
+// waiting for msg.
+func Deliver[M any](ctx context.Context, ps *PubSub, ch chan<- M, msg M) bool {
+	select {
+	case ch <- msg:
+	default:
+		select {
+		case ch <- msg:
+			ps.Receiving(ctx)
+		case <-ps.done:
+			return false
+		}
+	}
+	ps.Returned()
+	return true
+}
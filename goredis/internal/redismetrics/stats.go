@@ -0,0 +1,116 @@
+//
+// Package redismetrics
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  stats
+// @Date: 2026/10/17 21:30
+//
+
+package redismetrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/feymanlee/monitorit"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Stats collects the connection pool stats of a client in the background into gauges labeled by
+// instance_name.
+//
+// Stats of different clients share the gauges, each one owns the series of its own instance name
+// and deletes them on Stop.
+type Stats struct {
+	options      *Options
+	instanceName string
+	totalConns   *prometheus.GaugeVec
+	idleConns    *prometheus.GaugeVec
+	staleConns   *prometheus.GaugeVec
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewStats creates and registers the pool stats gauges of the client called instanceName.
+func NewStats(instanceName string, options *Options) (*Stats, error) {
+	stat := Stats{
+		options:      options,
+		instanceName: instanceName,
+	}
+	for _, gauge := range []struct {
+		target **prometheus.GaugeVec
+		name   string
+		help   string
+	}{
+		{&stat.totalConns, "pool_total_conns", "Number of total connections in the pool"},
+		{&stat.idleConns, "pool_idle_conns", "Number of idle connections in the pool"},
+		{&stat.staleConns, "pool_stale_conns", "Number of stale connections removed from the pool"},
+	} {
+		vec, err := monitorit.RegisterAs(options.Registerer, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: options.Namespace,
+			Subsystem: options.Subsystem,
+			Name:      gauge.name,
+			Help:      gauge.help,
+		}, statLabelNames))
+		if err != nil {
+			return nil, err
+		}
+		*gauge.target = vec
+	}
+
+	return &stat, nil
+}
+
+// Start starts collecting the stats returned by poolStats every StatInterval until ctx is done
+// or Stop is called. Calling Start while the stats are being collected is a no-op.
+func (s *Stats) Start(ctx context.Context, poolStats func() PoolStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done != nil {
+		select {
+		case <-s.done:
+		default:
+			return
+		}
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.run(ctx, poolStats, s.done)
+}
+
+// Stop stops collecting the stats and deletes the series of the client, it returns once the
+// collecting goroutine has exited.
+func (s *Stats) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	for _, gauge := range []*prometheus.GaugeVec{s.totalConns, s.idleConns, s.staleConns} {
+		gauge.DeleteLabelValues(s.instanceName)
+	}
+}
+
+func (s *Stats) run(ctx context.Context, poolStats func() PoolStats, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(s.options.StatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := poolStats()
+			s.totalConns.WithLabelValues(s.instanceName).Set(float64(stats.TotalConns))
+			s.idleConns.WithLabelValues(s.instanceName).Set(float64(stats.IdleConns))
+			s.staleConns.WithLabelValues(s.instanceName).Set(float64(stats.StaleConns))
+		}
+	}
+}
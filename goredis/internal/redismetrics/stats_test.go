@@ -0,0 +1,101 @@
+package redismetrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/goleak"
+)
+
+func newStats(t *testing.T) (*Stats, *prometheus.Registry) {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	options := DefaultOptions()
+	options.Registerer = registry
+	options.StatInterval = time.Millisecond
+	stats, err := NewStats("test", options)
+	if err != nil {
+		t.Fatalf("NewStats() error = %v", err)
+	}
+	return stats, registry
+}
+
+func poolStats() PoolStats {
+	return PoolStats{TotalConns: 2, IdleConns: 1}
+}
+
+func TestStatsStop(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	stats, registry := newStats(t)
+
+	ctx := context.Background()
+	stats.Start(ctx, poolStats)
+	// A second Start would leak its goroutine as Stop only stops the first one
+	stats.Start(ctx, poolStats)
+	for testutil.CollectAndCount(registry, "service_component_redis_pool_total_conns") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	stats.Stop()
+	if got := testutil.CollectAndCount(registry); got != 0 {
+		t.Errorf("series after Stop = %d, want 0", got)
+	}
+	stats.Stop()
+}
+
+func TestStatsStopWaits(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	stats, registry := newStats(t)
+
+	collecting, release := make(chan struct{}), make(chan struct{})
+	stats.Start(context.Background(), func() PoolStats {
+		select {
+		case collecting <- struct{}{}:
+			<-release
+		default:
+		}
+		return poolStats()
+	})
+	<-collecting
+	stopped := make(chan struct{})
+	go func() {
+		stats.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop() returned while the stats were being collected")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+	if got := testutil.CollectAndCount(registry); got != 0 {
+		t.Errorf("series after Stop = %d, want 0", got)
+	}
+}
+
+func TestStatsContextDone(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	stats, registry := newStats(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stats.Start(ctx, poolStats)
+	stats.mu.Lock()
+	done := stats.done
+	stats.mu.Unlock()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the goroutine didn't exit once ctx was done")
+	}
+
+	// Start collects again once the goroutine has exited
+	stats.Start(context.Background(), poolStats)
+	for testutil.CollectAndCount(registry, "service_component_redis_pool_total_conns") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	stats.Stop()
+}
@@ -0,0 +1,158 @@
+package goredis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics/metricstest"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestPubSubReceiveMessage(t *testing.T) {
+	server, client, hook, registry := newClient(t)
+	ctx := context.Background()
+
+	pubsub := hook.PubSub(client.Subscribe(ctx, "events"))
+	metricstest.WaitSubscribed(server, "events")
+	for _, payload := range []string{"hello", "world"} {
+		if err := client.Publish(ctx, "events", payload).Err(); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	for _, want := range []string{"hello", "world"} {
+		msg, err := pubsub.ReceiveMessage(ctx)
+		if err != nil {
+			t.Fatalf("ReceiveMessage() error = %v", err)
+		}
+		if msg.Payload != want {
+			t.Errorf("ReceiveMessage() = %q, want %q", msg.Payload, want)
+		}
+	}
+
+	for _, tt := range []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"service_component_redis_pubsub_messages_total", map[string]string{"channel": "events"}, 2},
+		{"service_component_redis_pubsub_receive_latency_sec", nil, 1},
+		{"service_component_redis_pubsub_subscriptions", nil, 1},
+	} {
+		if got := metricstest.Value(t, registry, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+	if err := pubsub.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := metricstest.Value(t, registry, "service_component_redis_pubsub_subscriptions", nil); got != 0 {
+		t.Errorf("pubsub_subscriptions after Close = %v, want 0", got)
+	}
+}
+
+func TestPubSubReceive(t *testing.T) {
+	_, client, hook, registry := newClient(t)
+	ctx := context.Background()
+
+	pubsub := hook.PubSub(client.Subscribe(ctx, "events"))
+	defer pubsub.Close()
+	msg, err := pubsub.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Receive() error = %v", err)
+	}
+	if _, ok := msg.(*redis.Subscription); !ok {
+		t.Fatalf("Receive() = %T, want a subscription", msg)
+	}
+	if err := client.Publish(ctx, "events", "hello").Err(); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if msg, err = pubsub.ReceiveTimeout(ctx, time.Second); err != nil {
+		t.Fatalf("ReceiveTimeout() error = %v", err)
+	}
+	if _, ok := msg.(*redis.Message); !ok {
+		t.Fatalf("ReceiveTimeout() = %T, want a message", msg)
+	}
+
+	if got := metricstest.Value(t, registry, "service_component_redis_pubsub_subscriptions", nil); got != 1 {
+		t.Errorf("pubsub_subscriptions = %v, want 1", got)
+	}
+	if got := metricstest.Value(t, registry, "service_component_redis_pubsub_messages_total", map[string]string{"channel": "events"}); got != 1 {
+		t.Errorf("pubsub_messages_total{channel=events} = %v, want 1", got)
+	}
+}
+
+func TestPubSubChannel(t *testing.T) {
+	server, client, hook, registry := newClient(t)
+	ctx := context.Background()
+
+	pubsub := hook.PubSub(client.Subscribe(ctx, "events"))
+	ch := pubsub.Channel(redis.WithChannelSize(10))
+	if pubsub.Channel() != ch {
+		t.Error("Channel() returned another channel")
+	}
+	metricstest.WaitSubscribed(server, "events")
+	for _, payload := range []string{"hello", "world"} {
+		if err := client.Publish(ctx, "events", payload).Err(); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	// The second message waits for the application busy with the first one
+	<-ch
+	time.Sleep(50 * time.Millisecond)
+	<-ch
+
+	for _, tt := range []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"service_component_redis_pubsub_messages_total", map[string]string{"channel": "events"}, 2},
+		{"service_component_redis_pubsub_receive_latency_sec", nil, 1},
+		// go-redis v8 doesn't pass the subscriptions to Channel
+		{"service_component_redis_pubsub_subscriptions", nil, 0},
+	} {
+		if got := metricstest.Value(t, registry, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+	if err := pubsub.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("the channel is still open after Close")
+	}
+}
+
+func TestPubSubChannelWithSubscriptions(t *testing.T) {
+	_, client, hook, registry := newClient(t)
+	ctx := context.Background()
+
+	pubsub := hook.PubSub(client.Subscribe(ctx, "events"))
+	ch := pubsub.ChannelWithSubscriptions(ctx, 10)
+	if msg := <-ch; msg.(*redis.Subscription).Count != 1 {
+		t.Fatalf("ChannelWithSubscriptions() first sent %v, want the subscription", msg)
+	}
+	if err := client.Publish(ctx, "events", "hello").Err(); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if msg := <-ch; msg.(*redis.Message).Payload != "hello" {
+		t.Fatalf("ChannelWithSubscriptions() sent %v, want the message", msg)
+	}
+
+	if got := metricstest.Value(t, registry, "service_component_redis_pubsub_subscriptions", nil); got != 1 {
+		t.Errorf("pubsub_subscriptions = %v, want 1", got)
+	}
+	if got := metricstest.Value(t, registry, "service_component_redis_pubsub_messages_total", map[string]string{"channel": "events"}); got != 1 {
+		t.Errorf("pubsub_messages_total{channel=events} = %v, want 1", got)
+	}
+	if err := pubsub.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := metricstest.Value(t, registry, "service_component_redis_pubsub_subscriptions", nil); got != 0 {
+		t.Errorf("pubsub_subscriptions after Close = %v, want 0", got)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("the channel is still open after Close")
+	}
+}
@@ -9,63 +9,46 @@
 package goredis
 
 import (
-	"time"
+	"context"
 
-	"github.com/feymanlee/monitorit"
-	"github.com/go-redis/redis/v8"
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics"
 )
 
+// Stats collects the connection pool stats of a client in the background, see StatsCollector
+// to read them at scrape time instead.
 type Stats struct {
-	options    *Options
-	totalConns prometheus.Gauge
-	idleConns  prometheus.Gauge
-	staleConns prometheus.Gauge
+	stats *redismetrics.Stats
 }
 
-func NewStat(instanceName string, opts ...Option) *Stats {
+// NewStat creates and registers the pool stats gauges of the client called instanceName.
+func NewStat(instanceName string, opts ...Option) (*Stats, error) {
 	options := DefaultOptions()
 	options.Merge(opts...)
-	stat := Stats{
-		options: options,
+	stats, err := redismetrics.NewStats(instanceName, options)
+	if err != nil {
+		return nil, err
 	}
-	statLabels := prometheus.Labels{
-		"instance_name": instanceName,
-	}
-	stat.totalConns = monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
-		Namespace:   options.Namespace,
-		Subsystem:   options.Subsystem,
-		Name:        "pool_total_conns",
-		Help:        "Number of total connections in the pool",
-		ConstLabels: statLabels,
-	})).(prometheus.Gauge)
-
-	stat.idleConns = monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
-		Namespace:   options.Namespace,
-		Subsystem:   options.Subsystem,
-		Name:        "pool_idle_conns",
-		Help:        "Number of idle connections in the pool",
-		ConstLabels: statLabels,
-	})).(prometheus.Gauge)
+	return &Stats{stats: stats}, nil
+}
 
-	stat.staleConns = monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
-		Namespace:   options.Namespace,
-		Subsystem:   options.Subsystem,
-		Name:        "pool_stale_conns",
-		Help:        "Number of stale connections removed from the pool",
-		ConstLabels: statLabels,
-	})).(prometheus.Gauge)
+// StartStat starts collecting the pool stats of redisClient in the background.
+//
+// Deprecated: use Start, which can be stopped.
+func (s *Stats) StartStat(redisClient PoolStatser) {
+	s.Start(context.Background(), redisClient)
+}
 
-	return &stat
+// Start starts collecting the pool stats of redisClient every StatInterval until ctx is done
+// or Stop is called. Calling Start while the stats are being collected is a no-op.
+//
+// The stats of cluster and ring clients are accumulated over all nodes, use StatsCollector
+// for a breakdown per node.
+func (s *Stats) Start(ctx context.Context, redisClient PoolStatser) {
+	s.stats.Start(ctx, pool{client: redisClient}.PoolStats)
 }
 
-func (s *Stats) StartStat(redisClient *redis.Client) {
-	go func() {
-		for range time.Tick(s.options.StatInterval) {
-			stats := redisClient.PoolStats()
-			s.totalConns.Set(float64(stats.TotalConns))
-			s.idleConns.Set(float64(stats.IdleConns))
-			s.staleConns.Set(float64(stats.StaleConns))
-		}
-	}()
+// Stop stops collecting the stats and deletes the series of the client, it returns once the
+// collecting goroutine has exited.
+func (s *Stats) Stop() {
+	s.stats.Stop()
 }
@@ -0,0 +1,21 @@
+//
+// Package redis
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  classifier
+// @Date: 2026/10/17 17:20
+//
+
+package goredis
+
+import (
+	"github.com/feymanlee/monitorit"
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrorClassifier returns the default classifier of go-redis errors, it recognizes the
+// go-redis client errors and Redis error replies and falls back to monitorit.DefaultErrorClassifier.
+func ErrorClassifier() monitorit.ErrorClassifier {
+	return redismetrics.ErrorClassifier(redis.Nil, redis.ErrClosed)
+}
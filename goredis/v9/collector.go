@@ -0,0 +1,110 @@
+//
+// Package redis
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  collector
+// @Date: 2026/10/17 17:20
+//
+
+package goredis
+
+import (
+	"context"
+
+	"github.com/feymanlee/monitorit"
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+type (
+	// PoolStatser is implemented by the clients exposing their connection pool stats, i.e.
+	// redis.Client, redis.ClusterClient, redis.Ring and the failover clients.
+	PoolStatser interface {
+		PoolStats() *redis.PoolStats
+	}
+
+	// shardIterator is implemented by the clients made of several nodes, i.e. redis.ClusterClient and redis.Ring.
+	shardIterator interface {
+		ForEachShard(ctx context.Context, fn func(ctx context.Context, client *redis.Client) error) error
+	}
+
+	// StatsCollector is a prometheus.Collector reading the connection pool stats of clients at
+	// scrape time, unlike Stats it needs no background goroutine and never exports stale values.
+	//
+	// A single collector serves any number of clients, labeled by their instance name, see
+	// AddClient and RemoveClient. The stats of cluster and ring clients are exported both
+	// accumulated over all nodes and per node, labeled by the node address. The nodes are iterated
+	// within 3 seconds, the failures to iterate them are counted by node_pool_stats_errors_total.
+	//
+	// The collectors of the goredis and goredis/v9 packages created with the same options add
+	// their clients to the same registered collector, so that both go-redis versions can be
+	// collected while migrating from one to the other.
+	StatsCollector struct {
+		collector *redismetrics.StatsCollector
+	}
+
+	// pool adapts a client to the pool the stats are read from.
+	pool struct {
+		client PoolStatser
+	}
+)
+
+// NewStatsCollector creates a collector of pool stats and registers it. Collectors created with
+// the same options share the registered one, the returned collector must not be registered again.
+func NewStatsCollector(opts ...Option) (*StatsCollector, error) {
+	options := DefaultOptions()
+	options.Merge(opts...)
+	collector, err := monitorit.RegisterAs(options.Registerer, redismetrics.NewStatsCollector(options))
+	if err != nil {
+		return nil, err
+	}
+	return &StatsCollector{collector: collector}, nil
+}
+
+// Describe implements prometheus.Collector.
+func (c *StatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+}
+
+// AddClient adds redisClient to the collected clients under instanceName, replacing any client
+// previously added with it.
+func (c *StatsCollector) AddClient(instanceName string, redisClient PoolStatser) {
+	c.collector.AddPool(instanceName, pool{client: redisClient})
+}
+
+// RemoveClient removes the client added under instanceName, its series are no longer exported.
+func (c *StatsCollector) RemoveClient(instanceName string) {
+	c.collector.RemovePool(instanceName)
+}
+
+// Collect implements prometheus.Collector.
+func (c *StatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collector.Collect(ch)
+}
+
+func (p pool) PoolStats() redismetrics.PoolStats {
+	return poolStats(p.client.PoolStats())
+}
+
+func (p pool) ForEachNode(ctx context.Context, fn func(node string, stats redismetrics.PoolStats)) error {
+	shards, ok := p.client.(shardIterator)
+	if !ok {
+		return nil
+	}
+	return shards.ForEachShard(ctx, func(ctx context.Context, client *redis.Client) error {
+		fn(client.Options().Addr, poolStats(client.PoolStats()))
+		return nil
+	})
+}
+
+func poolStats(stats *redis.PoolStats) redismetrics.PoolStats {
+	return redismetrics.PoolStats{
+		Hits:       stats.Hits,
+		Misses:     stats.Misses,
+		Timeouts:   stats.Timeouts,
+		TotalConns: stats.TotalConns,
+		IdleConns:  stats.IdleConns,
+		StaleConns: stats.StaleConns,
+	}
+}
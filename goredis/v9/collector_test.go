@@ -0,0 +1,77 @@
+package goredis_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredisv8 "github.com/feymanlee/monitorit/goredis"
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics/metricstest"
+	"github.com/feymanlee/monitorit/goredis/v9"
+	redisv8 "github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestStatsCollectorClients(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	collector, err := goredis.NewStatsCollector(goredis.WithRegisterer(registry))
+	if err != nil {
+		t.Fatalf("NewStatsCollector() error = %v", err)
+	}
+	shared, err := goredis.NewStatsCollector(goredis.WithRegisterer(registry))
+	if err != nil {
+		t.Fatalf("NewStatsCollector() error = %v", err)
+	}
+
+	for _, c := range []struct {
+		name      string
+		collector *goredis.StatsCollector
+	}{
+		{"cache", collector},
+		{"sessions", shared},
+	} {
+		client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+		defer client.Close()
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			t.Fatalf("Ping() error = %v", err)
+		}
+		c.collector.AddClient(c.name, client)
+	}
+	// Both clients are collected once, by the registered collector shared by both collectors
+	if got := testutil.CollectAndCount(registry, "service_component_redis_pool_total_conns"); got != 2 {
+		t.Errorf("pool_total_conns series = %d, want 2", got)
+	}
+	if got := metricstest.Value(t, registry, "service_component_redis_pool_total_conns", map[string]string{"instance_name": "sessions"}); got != 1 {
+		t.Errorf("pool_total_conns{instance_name=sessions} = %v, want 1", got)
+	}
+
+	collector.RemoveClient("cache")
+	if got := testutil.CollectAndCount(registry, "service_component_redis_pool_total_conns"); got != 1 {
+		t.Errorf("pool_total_conns series after RemoveClient = %d, want 1", got)
+	}
+}
+
+func TestStatsCollectorBothVersions(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	v8Collector, err := goredisv8.NewStatsCollector(goredisv8.WithRegisterer(registry))
+	if err != nil {
+		t.Fatalf("v8 NewStatsCollector() error = %v", err)
+	}
+	collector, err := goredis.NewStatsCollector(goredis.WithRegisterer(registry))
+	if err != nil {
+		t.Fatalf("NewStatsCollector() after the v8 one error = %v", err)
+	}
+
+	v8Client := redisv8.NewClient(&redisv8.Options{Addr: miniredis.RunT(t).Addr()})
+	defer v8Client.Close()
+	v8Collector.AddClient("legacy", v8Client)
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	defer client.Close()
+	collector.AddClient("cache", client)
+
+	if got := testutil.CollectAndCount(registry, "service_component_redis_pool_total_conns"); got != 2 {
+		t.Errorf("pool_total_conns series = %d, want 2", got)
+	}
+}
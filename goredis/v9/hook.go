@@ -0,0 +1,155 @@
+//
+// Package redis
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  hook
+// @Date: 2026/10/17 17:20
+//
+
+package goredis
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/feymanlee/monitorit"
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// Hook represents a go-redis v9 hook that exports metrics of commands, pipelines and dials.
+//
+// The following metrics are exported:
+//
+// - Single commands (not-pipelined)
+//   - Histogram of duration
+//   - Counter of errors
+//
+// - Payloads of commands, see WithPayloadSizes
+//   - Histogram of argument sizes
+//   - Histogram of reply sizes
+//
+// - Blocking commands, see WithBlockingCommands
+//   - Histogram of duration
+//
+// - Read commands, see WithCacheResults
+//   - Counter of hits and misses
+//
+// - Pub/Sub, see PubSub
+//   - Counter of messages
+//   - Gauge of subscriptions
+//   - Histogram of receive loop latency
+//
+// - Pipelined commands
+//   - Histogram of pipeline sizes
+//   - Counter of commands
+//   - Counter of errors
+//
+// - Dials of new connections
+//   - Histogram of duration
+//   - Counter of errors
+//
+// The duration of individual pipelined commands won't be collected, but the overall duration of the
+// pipeline will, with a pseudo-command called "pipeline", or "tx_pipeline" for the MULTI/EXEC
+// transactions, along with its number of commands. WithAmortizedPipelineDuration additionally
+// estimates the duration of each pipelined command.
+type Hook struct {
+	metrics      *redismetrics.Metrics
+	options      *Options
+	instanceName string
+	dialDuration monitorit.Histogram
+	dialErrors   monitorit.Counter
+}
+
+var _ redis.Hook = (*Hook)(nil)
+
+var (
+	dialLabelNames      = []string{"instance_name"}
+	dialErrorLabelNames = []string{"instance_name", "error"}
+)
+
+// NewHook creates a new go-redis hook instance and its metrics.
+func NewHook(instanceName string, opts ...Option) (*Hook, error) {
+	options := DefaultOptions()
+	options.Merge(opts...)
+	backend := options.Backend("github.com/feymanlee/monitorit/goredis/v9")
+	metrics, err := redismetrics.NewMetrics(instanceName, options, backend, redis.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dialDuration, err := backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "dial_duration_sec",
+		OTelName:   "db.client.connection.create_time",
+		Help:       "Histogram of the time taken to dial new Redis connections",
+		Unit:       "s",
+		LabelNames: dialLabelNames,
+		Buckets:    options.DurationBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dialErrors, err := backend.NewCounter(monitorit.MetricOpts{
+		Name:       "dial_errors",
+		OTelName:   "db.client.connection.create_errors",
+		Help:       "Number of Redis connection dials that have failed",
+		Unit:       "{error}",
+		LabelNames: dialErrorLabelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Hook{
+		metrics:      metrics,
+		options:      options,
+		instanceName: instanceName,
+		dialDuration: dialDuration,
+		dialErrors:   dialErrors,
+	}, nil
+}
+
+// DialHook implements redis.Hook, it records the duration and errors of connection dials.
+func (hook *Hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := time.Now()
+		conn, err := next(ctx, network, addr)
+		hook.dialDuration.Observe(ctx, time.Since(start).Seconds(), hook.instanceName)
+		if err != nil {
+			hook.dialErrors.Add(ctx, 1, hook.instanceName, hook.options.ErrorClassifier.Classify(err))
+		}
+		return conn, err
+	}
+}
+
+// ProcessHook implements redis.Hook, it records the duration and errors of single commands.
+func (hook *Hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		hook.metrics.RecordCommand(ctx, cmd, redismetrics.CommandErr(cmd, err), time.Since(start))
+		return err
+	}
+}
+
+// ProcessPipelineHook implements redis.Hook, it records the duration of the whole pipeline
+// and counts its commands and their errors.
+func (hook *Hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		hook.metrics.RecordPipeline(ctx, commands(cmds), err, time.Since(start))
+		return err
+	}
+}
+
+// commands returns cmds as the commands the metrics are recorded from.
+func commands(cmds []redis.Cmder) []redismetrics.Cmd {
+	commands := make([]redismetrics.Cmd, len(cmds))
+	for i, cmd := range cmds {
+		commands[i] = cmd
+	}
+	return commands
+}
@@ -0,0 +1,201 @@
+//
+// Package redis
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  options
+// @Date: 2026/10/17 17:20
+//
+
+package goredis
+
+import (
+	"time"
+
+	"github.com/feymanlee/monitorit"
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type (
+	// Options represents options to customize the exported metrics.
+	Options = redismetrics.Options
+
+	Option = redismetrics.Option
+)
+
+// DefaultOptions returns the default options.
+func DefaultOptions() *Options {
+	options := redismetrics.DefaultOptions()
+	options.ErrorClassifier = ErrorClassifier()
+	return options
+}
+
+// WithNamespace sets the namespace of all metrics.
+func WithNamespace(namespace string) Option {
+	return func(options *Options) {
+		options.Namespace = namespace
+	}
+}
+
+// WithSubsystem sets the subsystem of all metrics.
+func WithSubsystem(subsystem string) Option {
+	return func(options *Options) {
+		options.Subsystem = subsystem
+	}
+}
+
+// WithDurationBuckets sets the duration buckets of single commands metrics.
+func WithDurationBuckets(buckets []float64) Option {
+	return func(options *Options) {
+		options.DurationBuckets = buckets
+	}
+}
+
+// WithStatInterval sets the interval the pool stats are collected at by Stats.
+func WithStatInterval(interval time.Duration) Option {
+	return func(options *Options) {
+		options.StatInterval = interval
+	}
+}
+
+// WithPipelineSizeBuckets sets the buckets of the pipeline size metrics.
+func WithPipelineSizeBuckets(buckets []float64) Option {
+	return func(options *Options) {
+		options.PipelineSizeBuckets = buckets
+	}
+}
+
+// WithAmortizedPipelineDuration records the duration of each pipelined command estimated as
+// the duration of its pipeline divided by the number of commands in it.
+func WithAmortizedPipelineDuration() Option {
+	return func(options *Options) {
+		options.AmortizedPipelineDuration = true
+	}
+}
+
+// WithPayloadSizes records the size in bytes of the arguments and of the string, slice or map
+// replies of commands.
+func WithPayloadSizes() Option {
+	return func(options *Options) {
+		options.PayloadSizes = true
+	}
+}
+
+// WithSizeBuckets sets the buckets of the payload size metrics.
+func WithSizeBuckets(buckets []float64) Option {
+	return func(options *Options) {
+		options.SizeBuckets = buckets
+	}
+}
+
+// WithCacheResults sets whether the hits and misses of read commands like GET, HGET or MGET
+// are counted, they are by default.
+func WithCacheResults(enabled bool) Option {
+	return func(options *Options) {
+		options.CacheResults = enabled
+	}
+}
+
+// WithBlockingCommands sets the commands expected to block, like BLPOP, whose duration is recorded
+// by the blocking commands metrics instead of the single commands ones. XREAD and XREADGROUP are
+// only considered blocking with the BLOCK option.
+func WithBlockingCommands(commands ...string) Option {
+	return func(options *Options) {
+		options.BlockingCommands = commands
+	}
+}
+
+// WithBlockingBuckets sets the duration buckets of the blocking commands metrics.
+func WithBlockingBuckets(buckets []float64) Option {
+	return func(options *Options) {
+		options.BlockingBuckets = buckets
+	}
+}
+
+// WithBlockingCommandsExcluded doesn't record the duration of the blocking commands at all.
+func WithBlockingCommandsExcluded() Option {
+	return func(options *Options) {
+		options.ExcludeBlocking = true
+	}
+}
+
+// WithMaxChannels sets the maximum number of distinct channels and patterns labeling the
+// Pub/Sub messages metrics, the others are labeled "other". Zero or less means no limit.
+func WithMaxChannels(max int) Option {
+	return func(options *Options) {
+		options.MaxChannels = max
+	}
+}
+
+// WithRegisterer sets the registerer the metrics are registered with.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(options *Options) {
+		options.Registerer = registerer
+	}
+}
+
+// WithErrorClassifier sets the classifier mapping errors to the values of the error label.
+func WithErrorClassifier(classifier monitorit.ErrorClassifier) Option {
+	return func(options *Options) {
+		options.ErrorClassifier = classifier
+	}
+}
+
+// WithKeyPrefixLabel adds a key_prefix label to the command metrics, made of the first depth
+// segments of the first key of the commands split by delimiter, e.g. "session:*" for the key
+// "session:1234" with ":" and 1. To protect the cardinality, at most maxPrefixes distinct
+// prefixes are labeled, the others are labeled "other". The hook can't be created unless
+// maxPrefixes is positive.
+func WithKeyPrefixLabel(delimiter string, depth int, maxPrefixes int) Option {
+	return func(options *Options) {
+		options.KeyPrefixLabel = true
+		options.KeyPrefixDelimiter = delimiter
+		options.KeyPrefixDepth = depth
+		options.MaxKeyPrefixes = maxPrefixes
+	}
+}
+
+// WithDBLabel adds a db label to the command metrics with the index of the database selected
+// by the client, e.g. WithDBLabel(client.Options().DB).
+func WithDBLabel(db int) Option {
+	return func(options *Options) {
+		options.DBLabel = true
+		options.DB = db
+	}
+}
+
+// WithSlowThreshold counts the statements taking longer than threshold as slow queries,
+// and reports them to sink if it's not nil.
+func WithSlowThreshold(threshold time.Duration, sink monitorit.SlowQuerySink) Option {
+	return func(options *Options) {
+		options.SlowThreshold = threshold
+		options.SlowQuerySink = sink
+	}
+}
+
+// WithNativeHistograms enables native histograms with the given bucket factor, e.g. 1.1, and at
+// most maxBuckets buckets, zero meaning no limit. The classic buckets are still exported
+// for backward compatibility unless they are set to nil.
+func WithNativeHistograms(bucketFactor float64, maxBuckets uint32) Option {
+	return func(options *Options) {
+		options.NativeHistogramBucketFactor = bucketFactor
+		options.NativeHistogramMaxBuckets = maxBuckets
+	}
+}
+
+// WithTraceIDExtractor sets the function extracting the trace ID of the exemplars attached to
+// histogram observations, OpenTelemetry is used by default and nil disables the exemplars.
+func WithTraceIDExtractor(extractor monitorit.TraceIDExtractor) Option {
+	return func(options *Options) {
+		options.TraceIDExtractor = extractor
+	}
+}
+
+// WithMeterProvider records the metrics to the OpenTelemetry provider instead of Prometheus,
+// the Prometheus specific options are then ignored.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(options *Options) {
+		options.MeterProvider = provider
+	}
+}
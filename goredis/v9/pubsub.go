@@ -0,0 +1,157 @@
+//
+// Package redis
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  pubsub
+// @Date: 2026/10/17 20:10
+//
+
+package goredis
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// PubSub wraps a redis.PubSub to record the messages received with its Receive and Channel
+// methods, the number of channels and patterns subscribed to and the latency of the receive loop,
+// i.e. the time the application takes to get back to receiving after a message is returned to it.
+// With the Go channels, the latency is only recorded when the next message is already waiting for
+// the application.
+type PubSub struct {
+	*redis.PubSub
+	metrics *redismetrics.PubSub
+
+	chOnce sync.Once
+	msgCh  chan *redis.Message
+	allCh  chan interface{}
+}
+
+// PubSub wraps pubsub to record its metrics, e.g. hook.PubSub(client.Subscribe(ctx, "events")).
+func (hook *Hook) PubSub(pubsub *redis.PubSub) *PubSub {
+	return &PubSub{
+		PubSub:  pubsub,
+		metrics: hook.metrics.PubSub(),
+	}
+}
+
+// Receive returns the next message like redis.PubSub.Receive, recording it.
+func (ps *PubSub) Receive(ctx context.Context) (interface{}, error) {
+	return ps.ReceiveTimeout(ctx, 0)
+}
+
+// ReceiveTimeout returns the next message like redis.PubSub.ReceiveTimeout, recording it.
+func (ps *PubSub) ReceiveTimeout(ctx context.Context, timeout time.Duration) (interface{}, error) {
+	ps.metrics.Receiving(ctx)
+	msg, err := ps.receive(ctx, timeout)
+	if _, ok := msg.(*redis.Message); ok {
+		ps.metrics.Returned()
+	}
+	return msg, err
+}
+
+// ReceiveMessage returns the next message like redis.PubSub.ReceiveMessage, recording it along
+// with the subscriptions received meanwhile.
+func (ps *PubSub) ReceiveMessage(ctx context.Context) (*redis.Message, error) {
+	ps.metrics.Receiving(ctx)
+	for {
+		msg, err := ps.receive(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			// Ignore.
+		case *redis.Pong:
+			// Ignore.
+		case *redis.Message:
+			ps.metrics.Returned()
+			return msg, nil
+		default:
+			return nil, fmt.Errorf("redis: unknown message: %T", msg)
+		}
+	}
+}
+
+// receive receives the next message with redis.PubSub.ReceiveTimeout and records it.
+func (ps *PubSub) receive(ctx context.Context, timeout time.Duration) (interface{}, error) {
+	msg, err := ps.PubSub.ReceiveTimeout(ctx, timeout)
+	if err != nil {
+		return nil, err
+	}
+	ps.record(ctx, msg)
+	return msg, nil
+}
+
+// record records msg if it's a subscription or a message.
+func (ps *PubSub) record(ctx context.Context, msg interface{}) {
+	switch msg := msg.(type) {
+	case *redis.Subscription:
+		ps.metrics.Subscribed(ctx, msg.Count)
+	case *redis.Message:
+		ps.metrics.Received(ctx, msg.Channel, msg.Pattern)
+	}
+}
+
+// Channel returns a Go channel of the messages like redis.PubSub.Channel, recording them along
+// with the subscriptions.
+func (ps *PubSub) Channel(opts ...redis.ChannelOption) <-chan *redis.Message {
+	ps.chOnce.Do(func() {
+		ps.msgCh = make(chan *redis.Message)
+		go forward(ps, ps.PubSub.ChannelWithSubscriptions(opts...), ps.msgCh)
+	})
+	if ps.msgCh == nil {
+		panic(fmt.Errorf("redis: Channel can't be called after ChannelWithSubscriptions"))
+	}
+	return ps.msgCh
+}
+
+// ChannelSize is like Channel, but creates a Go channel with specified buffer size.
+//
+// Deprecated: use Channel(redis.WithChannelSize(size)).
+func (ps *PubSub) ChannelSize(size int) <-chan *redis.Message {
+	return ps.Channel(redis.WithChannelSize(size))
+}
+
+// ChannelWithSubscriptions returns a Go channel of the messages and subscriptions like
+// redis.PubSub.ChannelWithSubscriptions, recording them.
+func (ps *PubSub) ChannelWithSubscriptions(opts ...redis.ChannelOption) <-chan interface{} {
+	ps.chOnce.Do(func() {
+		ps.allCh = make(chan interface{})
+		go forward(ps, ps.PubSub.ChannelWithSubscriptions(opts...), ps.allCh)
+	})
+	if ps.allCh == nil {
+		panic(fmt.Errorf("redis: ChannelWithSubscriptions can't be called after Channel"))
+	}
+	return ps.allCh
+}
+
+// forward records the messages and subscriptions received from in and delivers the ones of type
+// M to out, which is closed once in is or the Pub/Sub is closed.
+func forward[M any](ps *PubSub, in <-chan interface{}, out chan<- M) {
+	defer close(out)
+	ctx := context.Background()
+	for msg := range in {
+		ps.record(ctx, msg)
+		if msg, ok := msg.(M); ok && !redismetrics.Deliver(ctx, ps.metrics, out, msg) {
+			break
+		}
+	}
+	// Drained for go-redis not to wait for the application to take the remaining messages
+	for range in {
+	}
+}
+
+// Close closes the wrapped redis.PubSub, its subscriptions are no longer counted.
+func (ps *PubSub) Close() error {
+	// Closed first for no subscription to be counted once they're reset
+	err := ps.PubSub.Close()
+	ps.metrics.Close()
+	return err
+}
@@ -0,0 +1,47 @@
+//
+// Package redis
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  stats
+// @Date: 2026/10/17 21:30
+//
+
+package goredis
+
+import (
+	"context"
+
+	"github.com/feymanlee/monitorit/goredis/internal/redismetrics"
+)
+
+// Stats collects the connection pool stats of a client in the background, see StatsCollector
+// to read them at scrape time instead.
+type Stats struct {
+	stats *redismetrics.Stats
+}
+
+// NewStat creates and registers the pool stats gauges of the client called instanceName.
+func NewStat(instanceName string, opts ...Option) (*Stats, error) {
+	options := DefaultOptions()
+	options.Merge(opts...)
+	stats, err := redismetrics.NewStats(instanceName, options)
+	if err != nil {
+		return nil, err
+	}
+	return &Stats{stats: stats}, nil
+}
+
+// Start starts collecting the pool stats of redisClient every StatInterval until ctx is done
+// or Stop is called. Calling Start while the stats are being collected is a no-op.
+//
+// The stats of cluster and ring clients are accumulated over all nodes, use StatsCollector
+// for a breakdown per node.
+func (s *Stats) Start(ctx context.Context, redisClient PoolStatser) {
+	s.stats.Start(ctx, pool{client: redisClient}.PoolStats)
+}
+
+// Stop stops collecting the stats and deletes the series of the client, it returns once the
+// collecting goroutine has exited.
+func (s *Stats) Stop() {
+	s.stats.Stop()
+}
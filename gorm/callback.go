@@ -10,10 +10,12 @@ package gorm
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/feymanlee/monitorit"
-	"github.com/prometheus/client_golang/prometheus"
 	"gorm.io/gorm"
 )
 
@@ -24,46 +26,92 @@ var startTimeKey = contextKey("start_time")
 type Callback struct {
 	options        *Options
 	instanceName   string
-	queryHistogram *prometheus.HistogramVec
-	queryCounter   *prometheus.CounterVec
-	errorCounter   *prometheus.CounterVec
+	queryHistogram monitorit.Histogram
+	queryCounter   monitorit.Counter
+	errorCounter   monitorit.Counter
+	rowsHistogram  monitorit.Histogram
+	slowCounter    monitorit.Counter
+	tables         *monitorit.LabelLimiter
 }
 
-var (
-	queryLabelNames = []string{"db_name", "command"}
-	errorLabelNames = []string{"db_name", "command", "error"}
-)
+var queryLabelNames = []string{"db_name", "command"}
 
-func NewCallback(dbName string, opts ...Option) *Callback {
+func NewCallback(dbName string, opts ...Option) (*Callback, error) {
 	options := DefaultOptions()
 	options.Merge(opts...)
 	c := Callback{
 		options:      options,
 		instanceName: dbName,
 	}
-	c.queryHistogram = monitorit.Register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: options.Namespace,
-		Subsystem: options.Subsystem,
-		Name:      "query_duration_sec",
-		Help:      "Histogram of GORM query duration in seconds",
-		Buckets:   options.DurationBuckets,
-	}, queryLabelNames)).(*prometheus.HistogramVec)
-
-	c.queryCounter = monitorit.Register(prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: options.Namespace,
-		Subsystem: options.Subsystem,
-		Name:      "query_total",
-		Help:      "Number of GORM queries total",
-	}, queryLabelNames)).(*prometheus.CounterVec)
-
-	c.errorCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "query_err_total",
-			Help: "Total number of GORM query errors",
-		},
-		errorLabelNames,
-	)
-	return &c
+	labelNames := queryLabelNames
+	if options.TableLabel {
+		if options.MaxTables <= 0 && len(options.TableAllowlist) == 0 {
+			return nil, fmt.Errorf("gorm: WithTableLabel needs a positive max number of tables or an allowlist, got %d", options.MaxTables)
+		}
+		labelNames = append(labelNames[:len(labelNames):len(labelNames)], "table")
+		c.tables = monitorit.NewLabelLimiter(options.MaxTables, options.TableAllowlist...)
+	}
+	errorLabelNames := append(labelNames[:len(labelNames):len(labelNames)], "error")
+
+	backend := options.backend()
+	var err error
+	c.queryHistogram, err = backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "query_duration_sec",
+		OTelName:   "db.client.operation.duration",
+		Help:       "Histogram of GORM query duration in seconds",
+		Unit:       "s",
+		LabelNames: labelNames,
+		Buckets:    options.DurationBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.queryCounter, err = backend.NewCounter(monitorit.MetricOpts{
+		Name:       "query_total",
+		OTelName:   "db.client.operations",
+		Help:       "Number of GORM queries total",
+		Unit:       "{operation}",
+		LabelNames: labelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.errorCounter, err = backend.NewCounter(monitorit.MetricOpts{
+		Name:       "query_err_total",
+		OTelName:   "db.client.operation.errors",
+		Help:       "Total number of GORM query errors",
+		Unit:       "{error}",
+		LabelNames: errorLabelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.rowsHistogram, err = backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "rows_affected",
+		OTelName:   "db.client.response.returned_rows",
+		Help:       "Histogram of rows affected or returned by GORM queries",
+		Unit:       "{row}",
+		LabelNames: labelNames,
+		Buckets:    options.RowsBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.slowCounter, err = backend.NewCounter(monitorit.MetricOpts{
+		Name:       "slow_queries_total",
+		OTelName:   "db.client.operation.slow",
+		Help:       "Number of GORM queries slower than the slow threshold",
+		Unit:       "{operation}",
+		LabelNames: labelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
 }
 
 func (c *Callback) Register(db *gorm.DB) (err error) {
@@ -103,6 +151,26 @@ func (c *Callback) Register(db *gorm.DB) (err error) {
 		return
 	}
 	err = db.Callback().Query().After("gorm:query").Register("monitor:after_query", c.recordDurationAndCount("query"))
+	if err != nil {
+		return
+	}
+
+	// Row, db.Row() and db.Rows()
+	err = db.Callback().Row().Before("gorm:row").Register("monitor:before_row", c.recordStartTime)
+	if err != nil {
+		return
+	}
+	err = db.Callback().Row().After("gorm:row").Register("monitor:after_row", c.recordDurationAndCount("row"))
+	if err != nil {
+		return
+	}
+
+	// Raw, db.Exec()
+	err = db.Callback().Raw().Before("gorm:raw").Register("monitor:before_raw", c.recordStartTime)
+	if err != nil {
+		return
+	}
+	err = db.Callback().Raw().After("gorm:raw").Register("monitor:after_raw", c.recordDurationAndCount("raw"))
 	return
 }
 
@@ -119,13 +187,81 @@ func (c *Callback) recordDurationAndCount(queryType string) func(*gorm.DB) {
 		if !ok {
 			return
 		}
-		duration := time.Since(startTime).Seconds()
-		c.queryCounter.WithLabelValues(c.instanceName, queryType).Inc()
-		c.queryHistogram.WithLabelValues(c.instanceName, queryType).Observe(duration)
+		elapsed := time.Since(startTime)
+		// Row and raw statements may run anything, label them by their SQL verb instead
+		command := queryType
+		if queryType == "row" || queryType == "raw" {
+			command = sqlCommand(db.Statement.SQL.String(), queryType)
+		}
+		labelValues := []string{c.instanceName, command}
+		if c.tables != nil {
+			labelValues = append(labelValues, c.tables.Value(tableName(db)))
+		}
+		ctx := db.Statement.Context
+		c.queryCounter.Add(ctx, 1, labelValues...)
+		c.queryHistogram.Observe(ctx, elapsed.Seconds(), labelValues...)
+		// Row sets RowsAffected to -1 as the rows are yet to be scanned
+		if db.RowsAffected >= 0 {
+			c.rowsHistogram.Observe(ctx, float64(db.RowsAffected), labelValues...)
+		}
 
-		// If there was an error, increment the error counter with the error reason
+		// If there was an error, increment the error counter with the error class
 		if db.Error != nil {
-			c.errorCounter.WithLabelValues(c.instanceName, queryType, db.Error.Error()).Inc()
+			c.errorCounter.Add(ctx, 1, append(labelValues, c.options.ErrorClassifier.Classify(db.Error))...)
 		}
+
+		if c.options.SlowThreshold > 0 && elapsed >= c.options.SlowThreshold {
+			c.slowCounter.Add(ctx, 1, labelValues...)
+			if c.options.SlowQuerySink != nil {
+				c.options.SlowQuerySink.Record(ctx, monitorit.SlowQuery{
+					Name:      c.instanceName,
+					Command:   command,
+					Statement: monitorit.NormalizeSQL(db.Statement.SQL.String(), sqlDialect(db)),
+					Duration:  elapsed,
+					Err:       db.Error,
+					Caller:    monitorit.Caller("gorm.io/"),
+				})
+			}
+		}
+	}
+}
+
+func tableName(db *gorm.DB) string {
+	if db.Statement.Table != "" {
+		return db.Statement.Table
+	}
+	if db.Statement.Schema != nil {
+		return db.Statement.Schema.Table
+	}
+	return ""
+}
+
+// sqlDialect returns the dialect the statements of db are normalized with.
+func sqlDialect(db *gorm.DB) monitorit.SQLDialect {
+	if db.Dialector != nil && db.Dialector.Name() == "mysql" {
+		return monitorit.DialectMySQL
+	}
+	return monitorit.DialectStandard
+}
+
+// sqlCommands are the SQL verbs used as command label values, anything else is labeled by the processor.
+var sqlCommands = map[string]struct{}{
+	"select": {}, "insert": {}, "update": {}, "delete": {}, "replace": {}, "merge": {}, "with": {},
+	"call": {}, "show": {}, "explain": {}, "create": {}, "alter": {}, "drop": {}, "truncate": {},
+	"begin": {}, "commit": {}, "rollback": {}, "savepoint": {}, "set": {},
+}
+
+func sqlCommand(sql, fallback string) string {
+	sql = strings.TrimLeft(sql, " \t\r\n(")
+	end := strings.IndexFunc(sql, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if end == -1 {
+		end = len(sql)
+	}
+	verb := strings.ToLower(sql[:end])
+	if _, ok := sqlCommands[verb]; ok {
+		return verb
 	}
+	return fallback
 }
@@ -0,0 +1,252 @@
+package gorm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCallbackQueryErrors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	callback, err := NewCallback("test", WithRegisterer(registry))
+	if err != nil {
+		t.Fatalf("NewCallback() error = %v", err)
+	}
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := callback.Register(db); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if err := db.Exec("SELECT FROM").Error; err == nil {
+		t.Fatal("Exec() of an invalid statement succeeded")
+	}
+	var rows []map[string]interface{}
+	if err := db.Table("missing").Find(&rows).Error; err == nil {
+		t.Fatal("Find() in a missing table succeeded")
+	}
+
+	expected := `
+# HELP service_component_gorm_query_err_total Total number of GORM query errors
+# TYPE service_component_gorm_query_err_total counter
+service_component_gorm_query_err_total{command="query",db_name="test",error="syntax"} 1
+service_component_gorm_query_err_total{command="select",db_name="test",error="syntax"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "service_component_gorm_query_err_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewCallbackConflictingType(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "service_component",
+		Subsystem: "gorm",
+		Name:      "query_total",
+		Help:      "Number of GORM queries total",
+	}, queryLabelNames))
+
+	_, err := NewCallback("test", WithRegisterer(registry))
+	if err == nil || !strings.Contains(err.Error(), "service_component_gorm_query_total") {
+		t.Errorf("NewCallback() error = %v, want one naming service_component_gorm_query_total", err)
+	}
+}
+
+func TestCallbackTableLabel(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opt  Option
+		want string
+	}{
+		{"max tables", WithTableLabel(2), `
+service_component_gorm_query_total{command="query",db_name="test",table="items"} 1
+service_component_gorm_query_total{command="query",db_name="test",table="orders"} 1
+service_component_gorm_query_total{command="query",db_name="test",table="other"} 1
+`},
+		{"allowlist", WithTableLabel(0, "orders", "users"), `
+service_component_gorm_query_total{command="query",db_name="test",table="orders"} 1
+service_component_gorm_query_total{command="query",db_name="test",table="other"} 1
+service_component_gorm_query_total{command="query",db_name="test",table="users"} 1
+`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			callback, err := NewCallback("test", WithRegisterer(registry), tt.opt)
+			if err != nil {
+				t.Fatalf("NewCallback() error = %v", err)
+			}
+			db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+			if err != nil {
+				t.Fatalf("gorm.Open() error = %v", err)
+			}
+			for _, table := range []string{"items", "orders", "users"} {
+				if err := db.Exec("CREATE TABLE " + table + " (id INTEGER PRIMARY KEY)").Error; err != nil {
+					t.Fatalf("CREATE TABLE %s error = %v", table, err)
+				}
+			}
+			if err := callback.Register(db); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+
+			for _, table := range []string{"items", "orders", "users"} {
+				var rows []map[string]interface{}
+				if err := db.Table(table).Find(&rows).Error; err != nil {
+					t.Fatalf("Find() in %s error = %v", table, err)
+				}
+			}
+			expected := `
+# HELP service_component_gorm_query_total Number of GORM queries total
+# TYPE service_component_gorm_query_total counter` + tt.want
+			if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "service_component_gorm_query_total"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCallbackTableLabelUnbounded(t *testing.T) {
+	for _, max := range []int{0, -1} {
+		if _, err := NewCallback("test", WithRegisterer(prometheus.NewRegistry()), WithTableLabel(max)); err == nil {
+			t.Errorf("NewCallback() with %d max tables and no allowlist succeeded", max)
+		}
+	}
+}
+
+// openCallbackDB opens a database with the tables users and orders recorded by a new callback.
+func openCallbackDB(t *testing.T, opts ...Option) (*gorm.DB, *prometheus.Registry) {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	callback, err := NewCallback("test", append([]Option{WithRegisterer(registry)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewCallback() error = %v", err)
+	}
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	for _, table := range []string{"users", "orders"} {
+		if err := db.Exec("CREATE TABLE " + table + " (id INTEGER PRIMARY KEY, name TEXT)").Error; err != nil {
+			t.Fatalf("CREATE TABLE %s error = %v", table, err)
+		}
+	}
+	if err := callback.Register(db); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	return db, registry
+}
+
+func TestCallbackRowAndRawCommands(t *testing.T) {
+	db, registry := openCallbackDB(t)
+
+	for _, sql := range []string{
+		"INSERT INTO users (id, name) VALUES (1, 'alice'), (2, 'bob')",
+		"\n\tselect 1",
+		"update users SET name = 'carol' WHERE id = 2",
+		"CREATE INDEX users_name ON users (name)",
+		// Not a SQL verb of the label values
+		"PRAGMA foreign_keys = ON",
+	} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatalf("Exec(%q) error = %v", sql, err)
+		}
+	}
+	var names []string
+	if err := db.Raw("SELECT name FROM users").Scan(&names).Error; err != nil {
+		t.Fatalf("Raw().Scan() error = %v", err)
+	}
+	var name string
+	if err := db.Table("users").Select("name").Where("id = ?", 1).Row().Scan(&name); err != nil {
+		t.Fatalf("Row().Scan() error = %v", err)
+	}
+
+	expected := `
+# HELP service_component_gorm_query_total Number of GORM queries total
+# TYPE service_component_gorm_query_total counter
+service_component_gorm_query_total{command="create",db_name="test"} 1
+service_component_gorm_query_total{command="insert",db_name="test"} 1
+service_component_gorm_query_total{command="raw",db_name="test"} 1
+service_component_gorm_query_total{command="select",db_name="test"} 3
+service_component_gorm_query_total{command="update",db_name="test"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "service_component_gorm_query_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCallbackRowsAffected(t *testing.T) {
+	db, registry := openCallbackDB(t, WithRowsBuckets([]float64{1}))
+
+	if err := db.Exec("INSERT INTO users (id, name) VALUES (1, 'alice'), (2, 'bob'), (3, 'carol')").Error; err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if err := db.Table("users").Where("id IN ?", []int{1, 2}).Update("name", "dave").Error; err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	var rows []map[string]interface{}
+	if err := db.Table("users").Find(&rows).Error; err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	// Row affects no rows before they're scanned
+	var name string
+	if err := db.Table("users").Select("name").Where("id = ?", 1).Row().Scan(&name); err != nil {
+		t.Fatalf("Row().Scan() error = %v", err)
+	}
+
+	expected := `
+# HELP service_component_gorm_rows_affected Histogram of rows affected or returned by GORM queries
+# TYPE service_component_gorm_rows_affected histogram
+service_component_gorm_rows_affected_bucket{command="insert",db_name="test",le="1"} 0
+service_component_gorm_rows_affected_bucket{command="insert",db_name="test",le="+Inf"} 1
+service_component_gorm_rows_affected_sum{command="insert",db_name="test"} 3
+service_component_gorm_rows_affected_count{command="insert",db_name="test"} 1
+service_component_gorm_rows_affected_bucket{command="query",db_name="test",le="1"} 0
+service_component_gorm_rows_affected_bucket{command="query",db_name="test",le="+Inf"} 1
+service_component_gorm_rows_affected_sum{command="query",db_name="test"} 3
+service_component_gorm_rows_affected_count{command="query",db_name="test"} 1
+service_component_gorm_rows_affected_bucket{command="update",db_name="test",le="1"} 0
+service_component_gorm_rows_affected_bucket{command="update",db_name="test",le="+Inf"} 1
+service_component_gorm_rows_affected_sum{command="update",db_name="test"} 2
+service_component_gorm_rows_affected_count{command="update",db_name="test"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "service_component_gorm_rows_affected"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCallbackNativeHistograms(t *testing.T) {
+	db, registry := openCallbackDB(t, WithNativeHistograms(1.1, 100))
+	if err := db.Exec("DELETE FROM users").Error; err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	var histograms int
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if h := metric.GetHistogram(); h != nil {
+				histograms++
+				if h.Schema == nil {
+					t.Errorf("%s isn't a native histogram", family.GetName())
+				}
+			}
+		}
+	}
+	if histograms != 2 {
+		t.Errorf("histograms = %d, want the query duration and rows affected", histograms)
+	}
+}
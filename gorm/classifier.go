@@ -0,0 +1,35 @@
+//
+// Package gorm
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  classifier
+// @Date: 2026/10/17 11:05
+//
+
+package gorm
+
+import (
+	"errors"
+
+	"github.com/feymanlee/monitorit"
+	"gorm.io/gorm"
+)
+
+// ErrorClassifier returns the default classifier of GORM errors, it recognizes the GORM
+// sentinel errors and falls back to monitorit.SQLErrorClassifier.
+func ErrorClassifier() monitorit.ErrorClassifier {
+	return monitorit.ChainErrorClassifiers(
+		monitorit.ErrorClassifierFunc(classifyGormError),
+		monitorit.SQLErrorClassifier(),
+	)
+}
+
+func classifyGormError(err error) string {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return monitorit.ErrorClassNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return monitorit.ErrorClassDuplicateKey
+	}
+	return monitorit.ErrorClassOther
+}
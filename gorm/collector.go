@@ -0,0 +1,116 @@
+//
+// Package gorm
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  collector
+// @Date: 2026/10/17 11:48
+//
+
+package gorm
+
+import (
+	"log"
+	"sync"
+
+	"github.com/feymanlee/monitorit"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// StatsCollector is a prometheus.Collector reading the connection pool stats of databases at
+// scrape time, unlike DBStats it needs no background goroutine and never exports stale values.
+//
+// A single collector serves any number of databases, labeled by their name, see AddDB and RemoveDB.
+type StatsCollector struct {
+	mu  sync.RWMutex
+	dbs map[string]*gorm.DB
+
+	maxOpenConnections *prometheus.Desc
+	openConnections    *prometheus.Desc
+	inUse              *prometheus.Desc
+	idle               *prometheus.Desc
+	waitCount          *prometheus.Desc
+	waitDuration       *prometheus.Desc
+	maxIdleClosed      *prometheus.Desc
+	maxLifetimeClosed  *prometheus.Desc
+	maxIdleTimeClosed  *prometheus.Desc
+}
+
+var statLabelNames = []string{"db_name"}
+
+// NewStatsCollector creates a collector of pool stats and registers it. Collectors created with
+// the same options share the registered one.
+func NewStatsCollector(opts ...Option) (*StatsCollector, error) {
+	options := DefaultOptions()
+	options.Merge(opts...)
+	newDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(options.Namespace, options.Subsystem, name), help, statLabelNames, nil)
+	}
+	c := &StatsCollector{
+		dbs:                make(map[string]*gorm.DB),
+		maxOpenConnections: newDesc("dbstats_max_open_connections", "Maximum number of open connections to the database."),
+		openConnections:    newDesc("dbstats_open_connections", "The number of established connections both in use and idle."),
+		inUse:              newDesc("dbstats_in_use", "The number of connections currently in use."),
+		idle:               newDesc("dbstats_idle", "The number of idle connections."),
+		waitCount:          newDesc("dbstats_wait_count_total", "The total number of connections waited for."),
+		waitDuration:       newDesc("dbstats_wait_duration_seconds_total", "The total time blocked waiting for a new connection in seconds."),
+		maxIdleClosed:      newDesc("dbstats_max_idle_closed_total", "The total number of connections closed due to SetMaxIdleConns."),
+		maxLifetimeClosed:  newDesc("dbstats_max_lifetime_closed_total", "The total number of connections closed due to SetConnMaxLifetime."),
+		maxIdleTimeClosed:  newDesc("dbstats_max_idletime_closed_total", "The total number of connections closed due to SetConnMaxIdleTime."),
+	}
+	return monitorit.RegisterAs(options.Registerer, c)
+}
+
+// Describe implements prometheus.Collector.
+func (c *StatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpenConnections
+	ch <- c.openConnections
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxLifetimeClosed
+	ch <- c.maxIdleTimeClosed
+}
+
+// AddDB adds db to the collected databases under name, replacing any database previously added with it.
+func (c *StatsCollector) AddDB(name string, db *gorm.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dbs[name] = db
+}
+
+// RemoveDB removes the database added under name, its series are no longer exported.
+func (c *StatsCollector) RemoveDB(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.dbs, name)
+}
+
+// Collect implements prometheus.Collector.
+func (c *StatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, db := range c.dbs {
+		c.collect(ch, name, db)
+	}
+}
+
+func (c *StatsCollector) collect(ch chan<- prometheus.Metric, dbName string, db *gorm.DB) {
+	dba, err := db.DB()
+	if err != nil {
+		log.Printf("gorm:prometheus failed to collect db status of %s, got error: %v", dbName, err)
+		return
+	}
+	dbStats := dba.Stats()
+	ch <- prometheus.MustNewConstMetric(c.maxOpenConnections, prometheus.GaugeValue, float64(dbStats.MaxOpenConnections), dbName)
+	ch <- prometheus.MustNewConstMetric(c.openConnections, prometheus.GaugeValue, float64(dbStats.OpenConnections), dbName)
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(dbStats.InUse), dbName)
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(dbStats.Idle), dbName)
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(dbStats.WaitCount), dbName)
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, dbStats.WaitDuration.Seconds(), dbName)
+	ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(dbStats.MaxIdleClosed), dbName)
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(dbStats.MaxLifetimeClosed), dbName)
+	ch <- prometheus.MustNewConstMetric(c.maxIdleTimeClosed, prometheus.CounterValue, float64(dbStats.MaxIdleTimeClosed), dbName)
+}
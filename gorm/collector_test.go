@@ -0,0 +1,109 @@
+package gorm
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/goleak"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newStatsCollector(t *testing.T) (*StatsCollector, *prometheus.Registry) {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	collector, err := NewStatsCollector(WithRegisterer(registry))
+	if err != nil {
+		t.Fatalf("NewStatsCollector() error = %v", err)
+	}
+	return collector, registry
+}
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func setMaxOpenConns(t *testing.T, db *gorm.DB, n int) {
+	t.Helper()
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("DB() error = %v", err)
+	}
+	sqlDB.SetMaxOpenConns(n)
+}
+
+func TestStatsCollectorScrapeTime(t *testing.T) {
+	ignored := goleak.IgnoreCurrent()
+	// Verified after the database is closed by the cleanup of openDB
+	t.Cleanup(func() {
+		goleak.VerifyNone(t, ignored)
+	})
+	collector, registry := newStatsCollector(t)
+	db := openDB(t)
+	collector.AddDB("test", db)
+
+	// Read at every scrape
+	for _, max := range []int{3, 5} {
+		setMaxOpenConns(t, db, max)
+		expected := `
+# HELP service_component_gorm_dbstats_max_open_connections Maximum number of open connections to the database.
+# TYPE service_component_gorm_dbstats_max_open_connections gauge
+service_component_gorm_dbstats_max_open_connections{db_name="test"} ` + strconv.Itoa(max) + `
+# HELP service_component_gorm_dbstats_wait_count_total The total number of connections waited for.
+# TYPE service_component_gorm_dbstats_wait_count_total counter
+service_component_gorm_dbstats_wait_count_total{db_name="test"} 0
+`
+		if err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+			"service_component_gorm_dbstats_max_open_connections", "service_component_gorm_dbstats_wait_count_total"); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStatsCollectorAddRemoveDB(t *testing.T) {
+	collector, registry := newStatsCollector(t)
+	orders, users := openDB(t), openDB(t)
+	setMaxOpenConns(t, orders, 3)
+	setMaxOpenConns(t, users, 5)
+	collector.AddDB("orders", orders)
+	collector.AddDB("users", users)
+
+	expected := `
+# HELP service_component_gorm_dbstats_max_open_connections Maximum number of open connections to the database.
+# TYPE service_component_gorm_dbstats_max_open_connections gauge
+service_component_gorm_dbstats_max_open_connections{db_name="orders"} 3
+service_component_gorm_dbstats_max_open_connections{db_name="users"} 5
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "service_component_gorm_dbstats_max_open_connections"); err != nil {
+		t.Error(err)
+	}
+
+	collector.RemoveDB("orders")
+	// Adding a database under the name of another one replaces it
+	collector.AddDB("users", orders)
+	expected = `
+# HELP service_component_gorm_dbstats_max_open_connections Maximum number of open connections to the database.
+# TYPE service_component_gorm_dbstats_max_open_connections gauge
+service_component_gorm_dbstats_max_open_connections{db_name="users"} 3
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "service_component_gorm_dbstats_max_open_connections"); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(registry); got != 9 {
+		t.Errorf("series = %d, want the 9 of users", got)
+	}
+}
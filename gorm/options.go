@@ -8,7 +8,13 @@
 
 package gorm
 
-import "time"
+import (
+	"time"
+
+	"github.com/feymanlee/monitorit"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
+)
 
 type (
 	// Options represents options to customize the exported metrics.
@@ -16,7 +22,20 @@ type (
 		Namespace       string
 		Subsystem       string
 		DurationBuckets []float64
+		RowsBuckets     []float64
 		StatInterval    time.Duration
+		Registerer      prometheus.Registerer
+		ErrorClassifier monitorit.ErrorClassifier
+		SlowThreshold   time.Duration
+		SlowQuerySink   monitorit.SlowQuerySink
+		TableLabel      bool
+		MaxTables       int
+		TableAllowlist  []string
+
+		NativeHistogramBucketFactor float64
+		NativeHistogramMaxBuckets   uint32
+		TraceIDExtractor            monitorit.TraceIDExtractor
+		MeterProvider               metric.MeterProvider
 	}
 
 	Option func(*Options)
@@ -28,7 +47,12 @@ func DefaultOptions() *Options {
 		Namespace:       "service_component",
 		Subsystem:       "gorm",
 		DurationBuckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1},
+		RowsBuckets:     []float64{1, 10, 100, 1000, 10000, 100000, 1000000},
 		StatInterval:    time.Second * 10,
+		Registerer:      prometheus.DefaultRegisterer,
+		ErrorClassifier: ErrorClassifier(),
+
+		TraceIDExtractor: monitorit.OpenTelemetryTraceID,
 	}
 }
 
@@ -59,9 +83,92 @@ func WithDurationBuckets(buckets []float64) Option {
 	}
 }
 
+// WithRowsBuckets sets the buckets of rows affected metrics.
+func WithRowsBuckets(buckets []float64) Option {
+	return func(options *Options) {
+		options.RowsBuckets = buckets
+	}
+}
+
 // WithStatInterval sets the duration buckets of single commands metrics.
 func WithStatInterval(interval time.Duration) Option {
 	return func(options *Options) {
 		options.StatInterval = interval
 	}
 }
+
+// WithRegisterer sets the registerer the metrics are registered with.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(options *Options) {
+		options.Registerer = registerer
+	}
+}
+
+// WithErrorClassifier sets the classifier mapping errors to the values of the error label.
+func WithErrorClassifier(classifier monitorit.ErrorClassifier) Option {
+	return func(options *Options) {
+		options.ErrorClassifier = classifier
+	}
+}
+
+// WithTableLabel adds a table label to the query metrics. To protect the cardinality, at most
+// maxTables distinct tables are labeled, and if allowlist isn't empty only the tables listed are,
+// the others are labeled "other". A maxTables of zero or less only means no limit along with an
+// allowlist, which bounds the tables, the callback can't be created otherwise.
+func WithTableLabel(maxTables int, allowlist ...string) Option {
+	return func(options *Options) {
+		options.TableLabel = true
+		options.MaxTables = maxTables
+		options.TableAllowlist = allowlist
+	}
+}
+
+// WithSlowThreshold counts the statements taking longer than threshold as slow queries,
+// and reports them to sink if it's not nil.
+func WithSlowThreshold(threshold time.Duration, sink monitorit.SlowQuerySink) Option {
+	return func(options *Options) {
+		options.SlowThreshold = threshold
+		options.SlowQuerySink = sink
+	}
+}
+
+// WithNativeHistograms enables native histograms with the given bucket factor, e.g. 1.1, and at
+// most maxBuckets buckets, zero meaning no limit. The classic buckets are still exported
+// for backward compatibility unless they are set to nil.
+func WithNativeHistograms(bucketFactor float64, maxBuckets uint32) Option {
+	return func(options *Options) {
+		options.NativeHistogramBucketFactor = bucketFactor
+		options.NativeHistogramMaxBuckets = maxBuckets
+	}
+}
+
+// WithTraceIDExtractor sets the function extracting the trace ID of the exemplars attached to
+// histogram observations, OpenTelemetry is used by default and nil disables the exemplars.
+func WithTraceIDExtractor(extractor monitorit.TraceIDExtractor) Option {
+	return func(options *Options) {
+		options.TraceIDExtractor = extractor
+	}
+}
+
+// WithMeterProvider records the metrics to the OpenTelemetry provider instead of Prometheus,
+// the Prometheus specific options are then ignored.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(options *Options) {
+		options.MeterProvider = provider
+	}
+}
+
+// backend returns the backend the metrics are recorded to.
+func (options *Options) backend() monitorit.Backend {
+	if options.MeterProvider != nil {
+		return monitorit.NewOpenTelemetryBackend(options.MeterProvider, "github.com/feymanlee/monitorit/gorm")
+	}
+	return monitorit.NewPrometheusBackend(monitorit.PrometheusOptions{
+		Namespace:                   options.Namespace,
+		Subsystem:                   options.Subsystem,
+		Registerer:                  options.Registerer,
+		NativeHistogramBucketFactor: options.NativeHistogramBucketFactor,
+		NativeHistogramMaxBuckets:   options.NativeHistogramMaxBuckets,
+		TraceIDExtractor:            options.TraceIDExtractor,
+	})
+}
@@ -9,7 +9,9 @@
 package gorm
 
 import (
+	"context"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/feymanlee/monitorit"
@@ -17,117 +19,140 @@ import (
 	"gorm.io/gorm"
 )
 
+// DBStats polls the connection pool stats of a database into gauges labeled by db_name.
+//
+// DBStats of different databases share the gauges, each one owns the series of its own name
+// and deletes them on Stop. See StatsCollector for a scrape time alternative.
 type DBStats struct {
 	options            *Options
-	maxOpenConnections prometheus.Gauge // Maximum number of open connections to the database.
+	dbName             string
+	maxOpenConnections *prometheus.GaugeVec // Maximum number of open connections to the database.
 
 	// Pool status
-	openConnections prometheus.Gauge // The number of established connections both in use and idle.
-	inUse           prometheus.Gauge // The number of connections currently in use.
-	idle            prometheus.Gauge // The number of idle connections.
+	openConnections *prometheus.GaugeVec // The number of established connections both in use and idle.
+	inUse           *prometheus.GaugeVec // The number of connections currently in use.
+	idle            *prometheus.GaugeVec // The number of idle connections.
 
 	// Counters
-	waitCount         prometheus.Gauge // The total number of connections waited for.
-	waitDuration      prometheus.Gauge // The total time blocked waiting for a new connection.
-	maxIdleClosed     prometheus.Gauge // The total number of connections closed due to SetMaxIdleConns.
-	maxLifetimeClosed prometheus.Gauge // The total number of connections closed due to SetConnMaxLifetime.
-	maxIdleTimeClosed prometheus.Gauge // The total number of connections closed due to SetConnMaxIdleTime.
+	waitCount         *prometheus.GaugeVec // The total number of connections waited for.
+	waitDuration      *prometheus.GaugeVec // The total time blocked waiting for a new connection.
+	maxIdleClosed     *prometheus.GaugeVec // The total number of connections closed due to SetMaxIdleConns.
+	maxLifetimeClosed *prometheus.GaugeVec // The total number of connections closed due to SetConnMaxLifetime.
+	maxIdleTimeClosed *prometheus.GaugeVec // The total number of connections closed due to SetConnMaxIdleTime.
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
-func NewStats(dbName string, opts ...Option) *DBStats {
-	statLabelNames := prometheus.Labels{
-		"db_name": dbName,
-	}
+func NewStats(dbName string, opts ...Option) (*DBStats, error) {
 	options := DefaultOptions()
 	options.Merge(opts...)
 	stats := &DBStats{
 		options: options,
-		maxOpenConnections: monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
+		dbName:  dbName,
+	}
+	for _, gauge := range []struct {
+		target **prometheus.GaugeVec
+		name   string
+		help   string
+	}{
+		{&stats.maxOpenConnections, "dbstats_max_open_connections", "Maximum number of open connections to the database."},
+		{&stats.openConnections, "dbstats_open_connections", "The number of established connections both in use and idle."},
+		{&stats.inUse, "dbstats_in_use", "The number of connections currently in use."},
+		{&stats.idle, "dbstats_idle", "The number of idle connections."},
+		{&stats.waitCount, "dbstats_wait_count", "The total number of connections waited for."},
+		{&stats.waitDuration, "dbstats_wait_duration", "The total time blocked waiting for a new connection."},
+		{&stats.maxIdleClosed, "dbstats_max_idle_closed", "The total number of connections closed due to SetMaxIdleConns."},
+		{&stats.maxLifetimeClosed, "dbstats_max_lifetime_closed", "The total number of connections closed due to SetConnMaxLifetime."},
+		{&stats.maxIdleTimeClosed, "dbstats_max_idletime_closed", "The total number of connections closed due to SetConnMaxIdleTime."},
+	} {
+		vec, err := monitorit.RegisterAs(options.Registerer, prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: options.Namespace,
 			Subsystem: options.Subsystem,
-			Name:      "dbstats_max_open_connections",
-
-			Help:        "Maximum number of open connections to the database.",
-			ConstLabels: statLabelNames,
-		})).(prometheus.Gauge),
-		openConnections: monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
-			Namespace:   options.Namespace,
-			Subsystem:   options.Subsystem,
-			Name:        "dbstats_open_connections",
-			Help:        "The number of established connections both in use and idle.",
-			ConstLabels: statLabelNames,
-		})).(prometheus.Gauge),
-		inUse: monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
-			Namespace:   options.Namespace,
-			Subsystem:   options.Subsystem,
-			Name:        "dbstats_in_use",
-			Help:        "The number of connections currently in use.",
-			ConstLabels: statLabelNames,
-		})).(prometheus.Gauge),
-		idle: monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
-			Namespace:   options.Namespace,
-			Subsystem:   options.Subsystem,
-			Name:        "dbstats_idle",
-			Help:        "The number of idle connections.",
-			ConstLabels: statLabelNames,
-		})).(prometheus.Gauge),
-		waitCount: monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
-			Namespace:   options.Namespace,
-			Subsystem:   options.Subsystem,
-			Name:        "dbstats_wait_count",
-			Help:        "The total number of connections waited for.",
-			ConstLabels: statLabelNames,
-		})).(prometheus.Gauge),
-		waitDuration: monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
-			Namespace:   options.Namespace,
-			Subsystem:   options.Subsystem,
-			Name:        "dbstats_wait_duration",
-			Help:        "The total time blocked waiting for a new connection.",
-			ConstLabels: statLabelNames,
-		})).(prometheus.Gauge),
-		maxIdleClosed: monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
-			Namespace:   options.Namespace,
-			Subsystem:   options.Subsystem,
-			Name:        "dbstats_max_idle_closed",
-			Help:        "The total number of connections closed due to SetMaxIdleConns.",
-			ConstLabels: statLabelNames,
-		})).(prometheus.Gauge),
-		maxLifetimeClosed: monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
-			Namespace:   options.Namespace,
-			Subsystem:   options.Subsystem,
-			Name:        "dbstats_max_lifetime_closed",
-			Help:        "The total number of connections closed due to SetConnMaxLifetime.",
-			ConstLabels: statLabelNames,
-		})).(prometheus.Gauge),
-		maxIdleTimeClosed: monitorit.Register(prometheus.NewGauge(prometheus.GaugeOpts{
-			Namespace:   options.Namespace,
-			Subsystem:   options.Subsystem,
-			Name:        "dbstats_max_idletime_closed",
-			Help:        "The total number of connections closed due to SetConnMaxIdleTime.",
-			ConstLabels: statLabelNames,
-		})).(prometheus.Gauge),
+			Name:      gauge.name,
+			Help:      gauge.help,
+		}, statLabelNames))
+		if err != nil {
+			return nil, err
+		}
+		*gauge.target = vec
 	}
 
-	return stats
+	return stats, nil
 }
 
+// StartStats starts collecting the stats of db in the background.
+//
+// Deprecated: use Start, which can be stopped.
 func (s *DBStats) StartStats(db *gorm.DB) {
-	go func() {
-		for range time.Tick(s.options.StatInterval) {
-			if dba, err := db.DB(); err == nil {
-				dbStats := dba.Stats()
-				s.maxOpenConnections.Set(float64(dbStats.MaxOpenConnections))
-				s.openConnections.Set(float64(dbStats.OpenConnections))
-				s.inUse.Set(float64(dbStats.InUse))
-				s.idle.Set(float64(dbStats.Idle))
-				s.waitCount.Set(float64(dbStats.WaitCount))
-				s.waitDuration.Set(float64(dbStats.WaitDuration))
-				s.maxIdleClosed.Set(float64(dbStats.MaxIdleClosed))
-				s.maxLifetimeClosed.Set(float64(dbStats.MaxLifetimeClosed))
-				s.maxIdleTimeClosed.Set(float64(dbStats.MaxIdleTimeClosed))
-			} else {
-				log.Printf("gorm:prometheus failed to collect db status, got error: %v", err)
-			}
+	s.Start(context.Background(), db)
+}
+
+// Start starts collecting the stats of db every StatInterval until ctx is done or Stop is called.
+// Calling Start while the stats are being collected is a no-op.
+func (s *DBStats) Start(ctx context.Context, db *gorm.DB) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done != nil {
+		select {
+		case <-s.done:
+		default:
+			return
 		}
-	}()
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.run(ctx, db, s.done)
+}
+
+// Stop stops collecting the stats and deletes the series of the database, it returns once the
+// collecting goroutine has exited.
+func (s *DBStats) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	for _, gauge := range []*prometheus.GaugeVec{
+		s.maxOpenConnections, s.openConnections, s.inUse, s.idle, s.waitCount,
+		s.waitDuration, s.maxIdleClosed, s.maxLifetimeClosed, s.maxIdleTimeClosed,
+	} {
+		gauge.DeleteLabelValues(s.dbName)
+	}
+}
+
+func (s *DBStats) run(ctx context.Context, db *gorm.DB, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(s.options.StatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.collect(db)
+		}
+	}
+}
+
+func (s *DBStats) collect(db *gorm.DB) {
+	if dba, err := db.DB(); err == nil {
+		dbStats := dba.Stats()
+		s.maxOpenConnections.WithLabelValues(s.dbName).Set(float64(dbStats.MaxOpenConnections))
+		s.openConnections.WithLabelValues(s.dbName).Set(float64(dbStats.OpenConnections))
+		s.inUse.WithLabelValues(s.dbName).Set(float64(dbStats.InUse))
+		s.idle.WithLabelValues(s.dbName).Set(float64(dbStats.Idle))
+		s.waitCount.WithLabelValues(s.dbName).Set(float64(dbStats.WaitCount))
+		s.waitDuration.WithLabelValues(s.dbName).Set(float64(dbStats.WaitDuration))
+		s.maxIdleClosed.WithLabelValues(s.dbName).Set(float64(dbStats.MaxIdleClosed))
+		s.maxLifetimeClosed.WithLabelValues(s.dbName).Set(float64(dbStats.MaxLifetimeClosed))
+		s.maxIdleTimeClosed.WithLabelValues(s.dbName).Set(float64(dbStats.MaxIdleTimeClosed))
+	} else {
+		log.Printf("gorm:prometheus failed to collect db status, got error: %v", err)
+	}
 }
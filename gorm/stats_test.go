@@ -0,0 +1,97 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/goleak"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newStats(t *testing.T) (*DBStats, *gorm.DB, *prometheus.Registry) {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	stats, err := NewStats("test", WithRegisterer(registry), WithStatInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewStats() error = %v", err)
+	}
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return stats, db, registry
+}
+
+// collecting returns the channel closed once the collecting goroutine of stats has exited.
+func collecting(stats *DBStats) chan struct{} {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	return stats.done
+}
+
+func TestDBStatsStop(t *testing.T) {
+	ignored := goleak.IgnoreCurrent()
+	// Verified after the database is closed by the cleanup of newStats
+	t.Cleanup(func() {
+		goleak.VerifyNone(t, ignored)
+	})
+	stats, db, registry := newStats(t)
+
+	ctx := context.Background()
+	stats.Start(ctx, db)
+	done := collecting(stats)
+	// A second Start would leak its goroutine as Stop only stops the first one
+	stats.Start(ctx, db)
+	if collecting(stats) != done {
+		t.Error("the second Start() started another goroutine")
+	}
+	for testutil.CollectAndCount(registry, "service_component_gorm_dbstats_open_connections") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	stats.Stop()
+	select {
+	case <-done:
+	default:
+		t.Error("Stop() returned before the goroutine exited")
+	}
+	if got := testutil.CollectAndCount(registry); got != 0 {
+		t.Errorf("series after Stop = %d, want 0", got)
+	}
+	stats.Stop()
+}
+
+func TestDBStatsContextDone(t *testing.T) {
+	ignored := goleak.IgnoreCurrent()
+	// Verified after the database is closed by the cleanup of newStats
+	t.Cleanup(func() {
+		goleak.VerifyNone(t, ignored)
+	})
+	stats, db, registry := newStats(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stats.Start(ctx, db)
+	done := collecting(stats)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the goroutine didn't exit once ctx was done")
+	}
+
+	// Start collects again once the goroutine has exited
+	stats.Start(context.Background(), db)
+	for testutil.CollectAndCount(registry, "service_component_gorm_dbstats_open_connections") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	stats.Stop()
+}
@@ -0,0 +1,49 @@
+//
+// Package kratos
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  client
+// @Date: 2026/10/17 15:02
+//
+
+package kratos
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Client returns a middleware recording the duration and the code/reason of every request
+// sent by the client, labeled by the target endpoint. It panics if the metrics can't be
+// registered, use NewMetrics and Metrics.Client to handle the error.
+func Client(opts ...Option) middleware.Middleware {
+	return mustNewMetrics(opts...).Client()
+}
+
+// Client returns a middleware recording the duration and the code/reason of every request
+// sent by the client, labeled by the target endpoint.
+func (m *Metrics) Client() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var (
+				kind      string
+				operation string
+				endpoint  string
+			)
+			startTime := time.Now()
+			if info, ok := transport.FromClientContext(ctx); ok {
+				kind = info.Kind().String()
+				operation = info.Operation()
+				endpoint = info.Endpoint()
+			}
+			reply, err := handler(ctx, req)
+			code, reason := requestStatus(err, m.options.ErrorClassifier)
+			m.clientRequests.Add(ctx, 1, kind, operation, endpoint, code, reason)
+			m.clientSeconds.Observe(ctx, time.Since(startTime).Seconds(), kind, operation, endpoint)
+			return reply, err
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package kratos_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestClient(t *testing.T) {
+	m, registry := newMetrics(t)
+	ctx := transport.NewClientContext(context.Background(), &transporter{
+		kind:      transport.KindHTTP,
+		endpoint:  "discovery:///users",
+		operation: "/users.v1.Users/GetUser",
+	})
+	for _, err := range []error{
+		nil,
+		errors.NotFound("USER_NOT_FOUND", "user 42 not found"),
+		context.DeadlineExceeded,
+	} {
+		handlerErr := err
+		if _, err := m.Client()(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "reply", handlerErr
+		})(ctx, "request"); err != handlerErr {
+			t.Errorf("Client() error = %v, want the invoker's %v", err, handlerErr)
+		}
+	}
+
+	expected := `
+# HELP service_client_requests_code_total The total number of requests sent to other services
+# TYPE service_client_requests_code_total counter
+service_client_requests_code_total{code="200",endpoint="discovery:///users",kind="http",operation="/users.v1.Users/GetUser",reason=""} 1
+service_client_requests_code_total{code="404",endpoint="discovery:///users",kind="http",operation="/users.v1.Users/GetUser",reason="USER_NOT_FOUND"} 1
+service_client_requests_code_total{code="500",endpoint="discovery:///users",kind="http",operation="/users.v1.Users/GetUser",reason="timeout"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "service_client_requests_code_total"); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(registry, "service_client_requests_duration_sec"); got != 1 {
+		t.Errorf("client_requests_duration_sec series = %d, want 1", got)
+	}
+	// The client requests aren't mixed with the server ones
+	if got := testutil.CollectAndCount(registry, "service_requests_code_total"); got != 0 {
+		t.Errorf("requests_code_total series = %d, want 0", got)
+	}
+}
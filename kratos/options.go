@@ -0,0 +1,138 @@
+//
+// Package kratos
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  options
+// @Date: 2026/10/17 10:12
+//
+
+package kratos
+
+import (
+	"github.com/feymanlee/monitorit"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type (
+	// Options represents options to customize the exported metrics.
+	Options struct {
+		Namespace       string
+		Subsystem       string
+		DurationBuckets []float64
+		SizeBuckets     []float64
+		Registerer      prometheus.Registerer
+		ErrorClassifier monitorit.ErrorClassifier
+
+		NativeHistogramBucketFactor float64
+		NativeHistogramMaxBuckets   uint32
+		TraceIDExtractor            monitorit.TraceIDExtractor
+		MeterProvider               metric.MeterProvider
+	}
+
+	Option func(*Options)
+)
+
+// DefaultOptions returns the default options.
+func DefaultOptions() *Options {
+	return &Options{
+		Namespace:       "service",
+		DurationBuckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.250, 0.5, 1},
+		SizeBuckets:     prometheus.ExponentialBuckets(64, 4, 8),
+		Registerer:      prometheus.DefaultRegisterer,
+		ErrorClassifier: monitorit.DefaultErrorClassifier(),
+
+		TraceIDExtractor: monitorit.OpenTelemetryTraceID,
+	}
+}
+
+func (options *Options) Merge(opts ...Option) {
+	for _, opt := range opts {
+		opt(options)
+	}
+}
+
+// WithNamespace sets the namespace of all metrics.
+func WithNamespace(namespace string) Option {
+	return func(options *Options) {
+		options.Namespace = namespace
+	}
+}
+
+// WithSubsystem sets the subsystem of all metrics.
+func WithSubsystem(subsystem string) Option {
+	return func(options *Options) {
+		options.Subsystem = subsystem
+	}
+}
+
+// WithDurationBuckets sets the duration buckets of requests metrics.
+func WithDurationBuckets(buckets []float64) Option {
+	return func(options *Options) {
+		options.DurationBuckets = buckets
+	}
+}
+
+// WithSizeBuckets sets the buckets of request and response size metrics, in bytes. The response
+// size is only recorded for gRPC.
+func WithSizeBuckets(buckets []float64) Option {
+	return func(options *Options) {
+		options.SizeBuckets = buckets
+	}
+}
+
+// WithRegisterer sets the registerer the metrics are registered with.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(options *Options) {
+		options.Registerer = registerer
+	}
+}
+
+// WithErrorClassifier sets the classifier mapping the errors that aren't Kratos errors
+// to the values of the reason label.
+func WithErrorClassifier(classifier monitorit.ErrorClassifier) Option {
+	return func(options *Options) {
+		options.ErrorClassifier = classifier
+	}
+}
+
+// WithNativeHistograms enables native histograms with the given bucket factor, e.g. 1.1, and at
+// most maxBuckets buckets, zero meaning no limit. The classic buckets are still exported
+// for backward compatibility unless they are set to nil.
+func WithNativeHistograms(bucketFactor float64, maxBuckets uint32) Option {
+	return func(options *Options) {
+		options.NativeHistogramBucketFactor = bucketFactor
+		options.NativeHistogramMaxBuckets = maxBuckets
+	}
+}
+
+// WithTraceIDExtractor sets the function extracting the trace ID of the exemplars attached to
+// histogram observations, OpenTelemetry is used by default and nil disables the exemplars.
+func WithTraceIDExtractor(extractor monitorit.TraceIDExtractor) Option {
+	return func(options *Options) {
+		options.TraceIDExtractor = extractor
+	}
+}
+
+// WithMeterProvider records the metrics to the OpenTelemetry provider instead of Prometheus,
+// the Prometheus specific options are then ignored.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(options *Options) {
+		options.MeterProvider = provider
+	}
+}
+
+// backend returns the backend the metrics are recorded to.
+func (options *Options) backend() monitorit.Backend {
+	if options.MeterProvider != nil {
+		return monitorit.NewOpenTelemetryBackend(options.MeterProvider, "github.com/feymanlee/monitorit/kratos")
+	}
+	return monitorit.NewPrometheusBackend(monitorit.PrometheusOptions{
+		Namespace:                   options.Namespace,
+		Subsystem:                   options.Subsystem,
+		Registerer:                  options.Registerer,
+		NativeHistogramBucketFactor: options.NativeHistogramBucketFactor,
+		NativeHistogramMaxBuckets:   options.NativeHistogramMaxBuckets,
+		TraceIDExtractor:            options.TraceIDExtractor,
+	})
+}
@@ -0,0 +1,74 @@
+//
+// Package kratos
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  recovery
+// @Date: 2026/10/17 15:36
+//
+
+package kratos
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+)
+
+// RecoveryHandler returns a handler for recovery.WithHandler counting the panics. It panics
+// if the metrics can't be registered, use NewMetrics and Metrics.RecoveryHandler to handle the error.
+func RecoveryHandler(opts ...Option) recovery.HandlerFunc {
+	return mustNewMetrics(opts...).RecoveryHandler()
+}
+
+// RecoveryHandler returns a handler for recovery.WithHandler counting the panics and replying
+// with an internal server error, the middleware already logs the panic and its stack.
+func (m *Metrics) RecoveryHandler() recovery.HandlerFunc {
+	return func(ctx context.Context, req, err interface{}) error {
+		m.PanicInc(ctx, err)
+		return recovery.ErrUnknownRequest
+	}
+}
+
+// panicReason returns the type of the panic value and the function of the application
+// that panicked, e.g. "runtime.boundsError@main.(*Server).Hello", which are bounded by the code.
+func panicReason(err interface{}) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	var frames []runtime.Frame
+	iter := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+
+	// The frames above runtime.gopanic are the recovering ones
+	for i := len(frames) - 1; i >= 0; i-- {
+		if frames[i].Function == "runtime.gopanic" {
+			frames = frames[i+1:]
+			break
+		}
+	}
+	function := "unknown"
+	for _, frame := range frames {
+		if !isLibraryFunction(frame.Function) {
+			function = frame.Function
+			break
+		}
+	}
+	return fmt.Sprintf("%T@%s", err, function)
+}
+
+func isLibraryFunction(function string) bool {
+	for _, prefix := range []string{"runtime.", "github.com/go-kratos/kratos/", "github.com/feymanlee/monitorit.", "github.com/feymanlee/monitorit/kratos."} {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
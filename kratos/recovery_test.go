@@ -0,0 +1,43 @@
+package kratos_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// getUser panics with an index out of range for any index but 0.
+func getUser(index int) string {
+	return []string{"alice"}[index]
+}
+
+func TestRecoveryHandler(t *testing.T) {
+	m, registry := newMetrics(t)
+	// Panics with different values from the same function share the reason
+	for _, index := range []int{1, 2} {
+		index := index
+		_, err := serve(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return getUser(index), nil
+		}, "request", recovery.Recovery(recovery.WithHandler(m.RecoveryHandler())), m.Server())
+		if se := errors.FromError(err); se.Code != 500 || se.Reason != "UNKNOWN" {
+			t.Errorf("error = %v, want a 500 UNKNOWN error", err)
+		}
+	}
+	_, _ = serve(func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("unexpected")
+	}, "request", recovery.Recovery(recovery.WithHandler(m.RecoveryHandler())), m.Server())
+
+	expected := `
+# HELP service_runtime_panic_total Total number of panics
+# TYPE service_runtime_panic_total counter
+service_runtime_panic_total{kind="grpc",operation="/helloworld.Greeter/SayHello",reason="runtime.boundsError@github.com/feymanlee/monitorit/kratos_test.getUser"} 2
+service_runtime_panic_total{kind="grpc",operation="/helloworld.Greeter/SayHello",reason="string@github.com/feymanlee/monitorit/kratos_test.TestRecoveryHandler.func2"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "service_runtime_panic_total"); err != nil {
+		t.Error(err)
+	}
+}
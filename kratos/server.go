@@ -0,0 +1,110 @@
+//
+// Package kratos
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  server
+// @Date: 2026/10/17 14:40
+//
+
+package kratos
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/feymanlee/monitorit"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/protobuf/proto"
+)
+
+// Server returns a middleware recording the duration and the code/reason of every request
+// handled by the server. It panics if the metrics can't be registered, use NewMetrics and
+// Metrics.Server to handle the error.
+func Server(opts ...Option) middleware.Middleware {
+	return mustNewMetrics(opts...).Server()
+}
+
+// Server returns a middleware recording the duration and the code/reason of every request
+// handled by the server, the number of requests in flight and the request and response sizes.
+//
+// The request size is the Content-Length of HTTP requests and the message size of gRPC requests.
+// The response size is only recorded for gRPC, HTTP responses are encoded after the middlewares
+// run and their size is unknown to them.
+func (m *Metrics) Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var (
+				kind      string
+				operation string
+			)
+			startTime := time.Now()
+			info, ok := transport.FromServerContext(ctx)
+			if ok {
+				kind = info.Kind().String()
+				operation = info.Operation()
+			}
+			m.inFlight.Add(ctx, 1, kind, operation)
+			defer m.inFlight.Add(ctx, -1, kind, operation)
+			if size, ok := requestSize(info, req); ok {
+				m.requestSizes.Observe(ctx, float64(size), kind, operation)
+			}
+
+			reply, err := handler(ctx, req)
+			code, reason := requestStatus(err, m.options.ErrorClassifier)
+			m.serverRequests.Add(ctx, 1, kind, operation, code, reason)
+			m.serverSeconds.Observe(ctx, time.Since(startTime).Seconds(), kind, operation)
+			if size, ok := responseSize(info, reply); err == nil && ok {
+				m.responseSizes.Observe(ctx, float64(size), kind, operation)
+			}
+			return reply, err
+		}
+	}
+}
+
+// requestSize returns the Content-Length of HTTP requests and the message size of gRPC requests.
+func requestSize(info transport.Transporter, req interface{}) (int, bool) {
+	if info == nil {
+		return 0, false
+	}
+	switch info.Kind() {
+	case transport.KindHTTP:
+		if ht, ok := info.(khttp.Transporter); ok && ht.Request().ContentLength >= 0 {
+			return int(ht.Request().ContentLength), true
+		}
+	case transport.KindGRPC:
+		if msg, ok := req.(proto.Message); ok {
+			return proto.Size(msg), true
+		}
+	}
+	return 0, false
+}
+
+// responseSize returns the message size of gRPC responses, HTTP responses are yet to be encoded
+// so their size isn't known.
+func responseSize(info transport.Transporter, reply interface{}) (int, bool) {
+	if info == nil || info.Kind() != transport.KindGRPC {
+		return 0, false
+	}
+	if msg, ok := reply.(proto.Message); ok {
+		return proto.Size(msg), true
+	}
+	return 0, false
+}
+
+// requestStatus returns the code and reason labels of a request, the reason of errors
+// that aren't Kratos errors is their class.
+func requestStatus(err error, classifier monitorit.ErrorClassifier) (string, string) {
+	if err == nil {
+		return "200", ""
+	}
+	se := errors.FromError(err)
+	reason := se.Reason
+	if reason == errors.UnknownReason {
+		reason = classifier.Classify(err)
+	}
+	return strconv.Itoa(int(se.Code)), reason
+}
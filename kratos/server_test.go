@@ -0,0 +1,232 @@
+package kratos_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/feymanlee/monitorit/kratos"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type (
+	// transporter is the transport of a request, implementing the HTTP transporter as well.
+	transporter struct {
+		kind      transport.Kind
+		endpoint  string
+		operation string
+		request   *http.Request
+	}
+
+	header http.Header
+)
+
+func (t *transporter) Kind() transport.Kind            { return t.kind }
+func (t *transporter) Endpoint() string                { return t.endpoint }
+func (t *transporter) Operation() string               { return t.operation }
+func (t *transporter) RequestHeader() transport.Header { return header{} }
+func (t *transporter) ReplyHeader() transport.Header   { return header{} }
+func (t *transporter) Request() *http.Request          { return t.request }
+func (t *transporter) PathTemplate() string            { return t.operation }
+func (h header) Get(key string) string                 { return http.Header(h).Get(key) }
+func (h header) Set(key string, value string)          { http.Header(h).Set(key, value) }
+func (h header) Add(key string, value string)          { http.Header(h).Add(key, value) }
+func (h header) Values(key string) []string            { return http.Header(h).Values(key) }
+func (h header) Keys() []string                        { return nil }
+
+func newMetrics(t *testing.T, opts ...kratos.Option) (*kratos.Metrics, *prometheus.Registry) {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	m, err := kratos.NewMetrics(append([]kratos.Option{kratos.WithRegisterer(registry)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewMetrics() error = %v", err)
+	}
+	return m, registry
+}
+
+// serve handles a request of the gRPC operation with handler behind the middlewares.
+func serve(handler middleware.Handler, req interface{}, middlewares ...middleware.Middleware) (interface{}, error) {
+	return serveTransport(&transporter{
+		kind:      transport.KindGRPC,
+		endpoint:  "grpc://127.0.0.1:9000",
+		operation: "/helloworld.Greeter/SayHello",
+	}, handler, req, middlewares...)
+}
+
+// serveTransport handles a request received through tr with handler behind the middlewares.
+func serveTransport(tr *transporter, handler middleware.Handler, req interface{}, middlewares ...middleware.Middleware) (interface{}, error) {
+	ctx := transport.NewServerContext(context.Background(), tr)
+	return middleware.Chain(middlewares...)(handler)(ctx, req)
+}
+
+// histogramSums returns the sum of the observations of each series of the histogram called name,
+// keyed by the value of their kind label.
+func histogramSums(t *testing.T, registry *prometheus.Registry, name string) map[string]float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	sums := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == "kind" {
+					sums[pair.GetValue()] = metric.GetHistogram().GetSampleSum()
+				}
+			}
+		}
+	}
+	return sums
+}
+
+func TestServer(t *testing.T) {
+	m, registry := newMetrics(t)
+	for _, err := range []error{
+		nil,
+		errors.NotFound("USER_NOT_FOUND", "user 42 not found"),
+		context.DeadlineExceeded,
+	} {
+		handlerErr := err
+		if _, err := serve(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "reply", handlerErr
+		}, "request", m.Server()); err != handlerErr {
+			t.Errorf("Server() error = %v, want the handler's %v", err, handlerErr)
+		}
+	}
+
+	expected := `
+# HELP service_requests_code_total The total number of processed requests
+# TYPE service_requests_code_total counter
+service_requests_code_total{code="200",kind="grpc",operation="/helloworld.Greeter/SayHello",reason=""} 1
+service_requests_code_total{code="404",kind="grpc",operation="/helloworld.Greeter/SayHello",reason="USER_NOT_FOUND"} 1
+service_requests_code_total{code="500",kind="grpc",operation="/helloworld.Greeter/SayHello",reason="timeout"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "service_requests_code_total"); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(registry, "service_requests_duration_sec"); got != 1 {
+		t.Errorf("requests_duration_sec series = %d, want 1", got)
+	}
+}
+
+func TestServerInFlight(t *testing.T) {
+	m, registry := newMetrics(t)
+	recovered := recovery.Recovery(recovery.WithHandler(m.RecoveryHandler()))
+	for _, panics := range []bool{false, true} {
+		panics := panics
+		_, _ = serve(func(ctx context.Context, req interface{}) (interface{}, error) {
+			if got := gaugeValue(t, registry, "service_in_flight_requests"); got != 1 {
+				t.Errorf("in_flight_requests while handling = %v, want 1", got)
+			}
+			if panics {
+				panic("unexpected")
+			}
+			return "reply", nil
+		}, "request", recovered, m.Server())
+		if got := gaugeValue(t, registry, "service_in_flight_requests"); got != 0 {
+			t.Errorf("in_flight_requests once handled = %v, want 0", got)
+		}
+	}
+}
+
+// gaugeValue returns the value of the first series of the gauge called name.
+func gaugeValue(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("%s wasn't gathered", name)
+	return 0
+}
+
+func TestServerSizes(t *testing.T) {
+	m, registry := newMetrics(t)
+	reply := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String("hello, world"), nil
+	}
+	if _, err := serve(reply, wrapperspb.String("hello"), m.Server()); err != nil {
+		t.Fatalf("Server() error = %v", err)
+	}
+	for _, contentLength := range []int64{42, -1} {
+		request, err := http.NewRequest(http.MethodPost, "http://127.0.0.1:8000/hello", nil)
+		if err != nil {
+			t.Fatalf("http.NewRequest() error = %v", err)
+		}
+		request.ContentLength = contentLength
+		if _, err := serveTransport(&transporter{
+			kind:      transport.KindHTTP,
+			operation: "/helloworld.Greeter/SayHello",
+			request:   request,
+		}, reply, wrapperspb.String("hello"), m.Server()); err != nil {
+			t.Fatalf("Server() error = %v", err)
+		}
+	}
+
+	wantRequests := map[string]float64{
+		"grpc": float64(proto.Size(wrapperspb.String("hello"))),
+		// Requests of unknown length aren't observed
+		"http": 42,
+	}
+	wantResponses := map[string]float64{
+		// HTTP responses are encoded after the middlewares run
+		"grpc": float64(proto.Size(wrapperspb.String("hello, world"))),
+	}
+	for _, tt := range []struct {
+		name string
+		want map[string]float64
+	}{
+		{"service_requests_size_bytes", wantRequests},
+		{"service_responses_size_bytes", wantResponses},
+	} {
+		if got := histogramSums(t, registry, tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s sums = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestServerNativeHistograms(t *testing.T) {
+	m, registry := newMetrics(t, kratos.WithNativeHistograms(1.1, 100))
+	if _, err := serve(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String("hello, world"), nil
+	}, wrapperspb.String("hello"), m.Server()); err != nil {
+		t.Fatalf("Server() error = %v", err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	var histograms int
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if h := metric.GetHistogram(); h != nil {
+				histograms++
+				if h.Schema == nil {
+					t.Errorf("%s isn't a native histogram", family.GetName())
+				}
+			}
+		}
+	}
+	if histograms != 3 {
+		t.Errorf("histograms = %d, want the duration and the request and response sizes", histograms)
+	}
+}
@@ -10,41 +10,140 @@ package kratos
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/feymanlee/monitorit"
 	"github.com/go-kratos/kratos/v2/transport"
-	"github.com/prometheus/client_golang/prometheus"
 )
 
-var (
-	serverMetricSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: "service",
-		Subsystem: "requests",
-		Name:      "duration_sec",
-		Help:      "server requests duration(sec).",
-		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.250, 0.5, 1},
-	}, []string{"kind", "operation"})
-
-	clientMetricRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: "service",
-		Subsystem: "requests",
-		Name:      "code_total",
-		Help:      "The total number of processed requests",
-	}, []string{"kind", "operation", "code", "reason"})
-
-	panicCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: "service",
-		Subsystem: "runtime",
-		Name:      "panic_total",
-		Help:      "Total number of panics",
-	}, []string{"kind", "operation", "reason"})
-)
+// Metrics holds the metrics of the server and client requests and of the panics.
+type Metrics struct {
+	options        *Options
+	serverSeconds  monitorit.Histogram
+	serverRequests monitorit.Counter
+	inFlight       monitorit.UpDownCounter
+	requestSizes   monitorit.Histogram
+	responseSizes  monitorit.Histogram
+	clientSeconds  monitorit.Histogram
+	clientRequests monitorit.Counter
+	panics         monitorit.Counter
+}
+
+// NewMetrics creates the kratos metrics with the configured backend, Prometheus metrics
+// created with the same options share the registered collectors.
+func NewMetrics(opts ...Option) (*Metrics, error) {
+	options := DefaultOptions()
+	options.Merge(opts...)
+	m := Metrics{
+		options: options,
+	}
+	backend := options.backend()
+	var err error
+	m.serverSeconds, err = backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "requests_duration_sec",
+		OTelName:   "rpc.server.duration",
+		Help:       "server requests duration(sec).",
+		Unit:       "s",
+		LabelNames: []string{"kind", "operation"},
+		Buckets:    options.DurationBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.serverRequests, err = backend.NewCounter(monitorit.MetricOpts{
+		Name:       "requests_code_total",
+		OTelName:   "rpc.server.requests",
+		Help:       "The total number of processed requests",
+		Unit:       "{request}",
+		LabelNames: []string{"kind", "operation", "code", "reason"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.inFlight, err = backend.NewUpDownCounter(monitorit.MetricOpts{
+		Name:       "in_flight_requests",
+		OTelName:   "rpc.server.active_requests",
+		Help:       "The number of requests being handled",
+		Unit:       "{request}",
+		LabelNames: []string{"kind", "operation"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.requestSizes, err = backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "requests_size_bytes",
+		OTelName:   "rpc.server.request.size",
+		Help:       "server requests size(bytes).",
+		Unit:       "By",
+		LabelNames: []string{"kind", "operation"},
+		Buckets:    options.SizeBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-func init() {
-	prometheus.MustRegister(serverMetricSeconds, clientMetricRequests, panicCounter)
+	m.responseSizes, err = backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "responses_size_bytes",
+		OTelName:   "rpc.server.response.size",
+		Help:       "server gRPC responses size(bytes).",
+		Unit:       "By",
+		LabelNames: []string{"kind", "operation"},
+		Buckets:    options.SizeBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.clientSeconds, err = backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "client_requests_duration_sec",
+		OTelName:   "rpc.client.duration",
+		Help:       "client requests duration(sec).",
+		Unit:       "s",
+		LabelNames: []string{"kind", "operation", "endpoint"},
+		Buckets:    options.DurationBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.clientRequests, err = backend.NewCounter(monitorit.MetricOpts{
+		Name:       "client_requests_code_total",
+		OTelName:   "rpc.client.requests",
+		Help:       "The total number of requests sent to other services",
+		Unit:       "{request}",
+		LabelNames: []string{"kind", "operation", "endpoint", "code", "reason"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.panics, err = backend.NewCounter(monitorit.MetricOpts{
+		Name:       "runtime_panic_total",
+		OTelName:   "rpc.server.panics",
+		Help:       "Total number of panics",
+		Unit:       "{panic}",
+		LabelNames: []string{"kind", "operation", "reason"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// mustNewMetrics is like NewMetrics but panics if the metrics can't be registered.
+func mustNewMetrics(opts ...Option) *Metrics {
+	m, err := NewMetrics(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return m
 }
 
-func PanicInc(ctx context.Context, err interface{}) {
+// PanicInc counts a panic recovered while handling the request of ctx, it must be called
+// from the deferred function recovering the panic to find where it was raised.
+func (m *Metrics) PanicInc(ctx context.Context, err interface{}) {
 	var (
 		kind      string
 		operation string
@@ -53,9 +152,5 @@ func PanicInc(ctx context.Context, err interface{}) {
 		kind = info.Kind().String()
 		operation = info.Operation()
 	}
-	panicCounter.With(prometheus.Labels{
-		"kind":      kind,
-		"operation": operation,
-		"reason":    fmt.Sprintf("%s", err),
-	}).Inc()
+	m.panics.Add(ctx, 1, kind, operation, panicReason(err))
 }
@@ -0,0 +1,75 @@
+package kratos_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/feymanlee/monitorit/kratos"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNewMetricsOptions(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	opts := []kratos.Option{
+		kratos.WithRegisterer(registry),
+		kratos.WithNamespace("shop"),
+		kratos.WithSubsystem("api"),
+		kratos.WithDurationBuckets([]float64{1, 2}),
+	}
+	m, err := kratos.NewMetrics(opts...)
+	if err != nil {
+		t.Fatalf("NewMetrics() error = %v", err)
+	}
+	// Metrics created with the same options share the registered collectors
+	shared, err := kratos.NewMetrics(opts...)
+	if err != nil {
+		t.Fatalf("NewMetrics() with the same options error = %v", err)
+	}
+	for _, m := range []*kratos.Metrics{m, shared} {
+		if _, err := serve(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "reply", nil
+		}, "request", m.Server()); err != nil {
+			t.Fatalf("Server() error = %v", err)
+		}
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	var histogram bool
+	for _, family := range families {
+		if family.GetName() != "shop_api_requests_duration_sec" {
+			continue
+		}
+		histogram = true
+		h := family.GetMetric()[0].GetHistogram()
+		if got := h.GetSampleCount(); got != 2 {
+			t.Errorf("requests_duration_sec samples = %d, want 2", got)
+		}
+		if got := len(h.GetBucket()); got != 2 {
+			t.Errorf("requests_duration_sec buckets = %d, want the 2 configured", got)
+		}
+	}
+	if !histogram {
+		t.Error("shop_api_requests_duration_sec wasn't gathered")
+	}
+	if got := testutil.CollectAndCount(registry, "service_requests_duration_sec"); got != 0 {
+		t.Errorf("service_requests_duration_sec series = %d, want the namespace and subsystem applied", got)
+	}
+}
+
+func TestNewMetricsRegistersNothingByDefault(t *testing.T) {
+	// Importing the package registers nothing, the metrics are only registered by NewMetrics
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if strings.HasPrefix(family.GetName(), "service_") {
+			t.Errorf("%s is registered on the default registry", family.GetName())
+		}
+	}
+}
@@ -0,0 +1,69 @@
+//
+// Package monitorit
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  limiter
+// @Date: 2026/10/17 13:20
+//
+
+package monitorit
+
+import "sync"
+
+// LabelValueOther replaces label values rejected by a LabelLimiter.
+const LabelValueOther = "other"
+
+// LabelLimiter guards the cardinality of a label whose values come from user input,
+// like table names or key prefixes.
+type LabelLimiter struct {
+	mu        sync.RWMutex
+	allowlist map[string]struct{}
+	seen      map[string]struct{}
+	max       int
+}
+
+// NewLabelLimiter creates a limiter accepting at most max distinct values, a max of zero or less
+// means no limit. If allowlist isn't empty, only the values listed are accepted.
+func NewLabelLimiter(max int, allowlist ...string) *LabelLimiter {
+	l := &LabelLimiter{
+		seen: make(map[string]struct{}),
+		max:  max,
+	}
+	if len(allowlist) > 0 {
+		l.allowlist = make(map[string]struct{}, len(allowlist))
+		for _, value := range allowlist {
+			l.allowlist[value] = struct{}{}
+		}
+	}
+	return l
+}
+
+// Value returns value if it's accepted, LabelValueOther otherwise.
+func (l *LabelLimiter) Value(value string) string {
+	if l.allowlist != nil {
+		if _, ok := l.allowlist[value]; !ok {
+			return LabelValueOther
+		}
+	}
+	if l.max <= 0 {
+		return value
+	}
+
+	l.mu.RLock()
+	_, ok := l.seen[value]
+	l.mu.RUnlock()
+	if ok {
+		return value
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok = l.seen[value]; ok {
+		return value
+	}
+	if len(l.seen) >= l.max {
+		return LabelValueOther
+	}
+	l.seen[value] = struct{}{}
+	return value
+}
@@ -0,0 +1,61 @@
+package monitorit
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestLabelLimiter(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		max       int
+		allowlist []string
+		values    []string
+		want      []string
+	}{
+		{"under max", 2, nil, []string{"users", "orders", "users"}, []string{"users", "orders", "users"}},
+		{"over max", 2, nil, []string{"users", "orders", "items", "users"}, []string{"users", "orders", "other", "users"}},
+		{"max of one", 1, nil, []string{"users", "orders", "users"}, []string{"users", "other", "users"}},
+		{"no max", 0, nil, []string{"users", "orders", "items"}, []string{"users", "orders", "items"}},
+		{"negative max", -1, nil, []string{"users", "orders"}, []string{"users", "orders"}},
+		{"allowlist", 0, []string{"users", "orders"}, []string{"users", "items", "orders"}, []string{"users", "other", "orders"}},
+		{"allowlist and max", 1, []string{"users", "orders"}, []string{"items", "orders", "users", "orders"}, []string{"other", "orders", "other", "orders"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewLabelLimiter(tt.max, tt.allowlist...)
+			var got []string
+			for _, value := range tt.values {
+				got = append(got, limiter.Value(value))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Value() of %v = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelLimiterConcurrent(t *testing.T) {
+	limiter := NewLabelLimiter(10)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		accepted = make(map[string]struct{})
+	)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(value string) {
+			defer wg.Done()
+			if got := limiter.Value(value); got != LabelValueOther {
+				mu.Lock()
+				accepted[got] = struct{}{}
+				mu.Unlock()
+			}
+		}(strconv.Itoa(i))
+	}
+	wg.Wait()
+	if len(accepted) != 10 {
+		t.Errorf("%d values accepted, want 10", len(accepted))
+	}
+}
@@ -8,17 +8,61 @@
 
 package monitorit
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"fmt"
+	"strings"
 
-func Register(collector prometheus.Collector) prometheus.Collector {
-	err := prometheus.DefaultRegisterer.Register(collector)
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Register registers the collector with the given registerer, prometheus.DefaultRegisterer
+// is used when registerer is nil. If an equal collector has already been registered,
+// the existing one is returned so that it can be shared.
+func Register(registerer prometheus.Registerer, collector prometheus.Collector) (prometheus.Collector, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	err := registerer.Register(collector)
 	if err == nil {
-		return collector
+		return collector, nil
 	}
 
 	if arErr, ok := err.(prometheus.AlreadyRegisteredError); ok {
-		return arErr.ExistingCollector
+		return arErr.ExistingCollector, nil
 	}
 
-	panic(err)
+	return nil, err
+}
+
+// RegisterAs registers the collector like Register, the existing collector is only shared if it
+// has the same type, otherwise an error naming the conflicting metrics is returned.
+func RegisterAs[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	registered, err := Register(registerer, collector)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	existing, ok := registered.(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("monitorit: %s already registered by a %T, not a %T", describe(collector), registered, collector)
+	}
+	return existing, nil
+}
+
+// describe returns the names of the metrics described by collector.
+func describe(collector prometheus.Collector) string {
+	ch := make(chan *prometheus.Desc)
+	go func() {
+		collector.Describe(ch)
+		close(ch)
+	}()
+	var names []string
+	for desc := range ch {
+		// Desc doesn't expose its name but its String starts with it: Desc{fqName: "name", ...
+		_, name, _ := strings.Cut(desc.String(), `fqName: "`)
+		name, _, _ = strings.Cut(name, `"`)
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
 }
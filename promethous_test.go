@@ -0,0 +1,43 @@
+package monitorit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRegisterAs(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	newCounter := func() *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "Number of requests"}, []string{"code"})
+	}
+
+	counter, err := RegisterAs(registry, newCounter())
+	if err != nil {
+		t.Fatalf("RegisterAs() error = %v", err)
+	}
+	shared, err := RegisterAs(registry, newCounter())
+	if err != nil {
+		t.Fatalf("RegisterAs() of an equal collector error = %v", err)
+	}
+	if shared != counter {
+		t.Error("RegisterAs() of an equal collector didn't return the registered one")
+	}
+
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "requests_total", Help: "Number of requests"}, []string{"code"})
+	if _, err := RegisterAs(registry, gauge); err == nil || !strings.Contains(err.Error(), "requests_total") {
+		t.Errorf("RegisterAs() of a conflicting collector type error = %v, want one naming requests_total", err)
+	}
+}
+
+func TestPrometheusBackendConflictingType(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	backend := NewPrometheusBackend(PrometheusOptions{Namespace: "test", Registerer: registry})
+	if _, err := backend.NewUpDownCounter(MetricOpts{Name: "queries", Help: "Number of queries", LabelNames: []string{"command"}}); err != nil {
+		t.Fatalf("NewUpDownCounter() error = %v", err)
+	}
+	if _, err := backend.NewCounter(MetricOpts{Name: "queries", Help: "Number of queries", LabelNames: []string{"command"}}); err == nil {
+		t.Error("NewCounter() over a gauge succeeded")
+	}
+}
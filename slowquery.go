@@ -0,0 +1,143 @@
+//
+// Package monitorit
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  slowquery
+// @Date: 2026/10/17 14:02
+//
+
+package monitorit
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+type (
+	// SlowQuery describes a statement that took longer than the slow threshold.
+	SlowQuery struct {
+		Name      string        // The db or instance name.
+		Command   string        // The command label value.
+		Statement string        // The normalized SQL or the Redis command with its arguments redacted.
+		Duration  time.Duration // The time the statement took.
+		Err       error         // The error of the statement, if any.
+		Caller    string        // The file:line of the application code running the statement.
+	}
+
+	// SlowQuerySink receives the slow queries.
+	SlowQuerySink interface {
+		Record(ctx context.Context, query SlowQuery)
+	}
+
+	// SlowQuerySinkFunc is an adapter to use an ordinary function as SlowQuerySink.
+	SlowQuerySinkFunc func(ctx context.Context, query SlowQuery)
+)
+
+// Record calls f(ctx, query).
+func (f SlowQuerySinkFunc) Record(ctx context.Context, query SlowQuery) {
+	f(ctx, query)
+}
+
+// SQLDialect selects how NormalizeSQL reads the quotes of a statement.
+type SQLDialect int
+
+const (
+	// DialectStandard reads double quotes as identifiers, which are kept, e.g. PostgreSQL and SQLite.
+	DialectStandard SQLDialect = iota
+	// DialectMySQL reads double quotes as strings, which are redacted, as MySQL does by default.
+	DialectMySQL
+)
+
+var (
+	// sqlTokenPattern matches, in order, the placeholders and quoted identifiers that are kept,
+	// then the string, hex and number literals that are redacted. Strings may have a prefix,
+	// e.g. E'' or N'', and escape quotes by doubling them or with a backslash.
+	sqlTokenPattern = regexp.MustCompile(`\$[0-9]+|` +
+		`"(?:[^"\\]|""|\\.)*"|` +
+		`(?:\b[EeNnXxBb])?'(?:[^'\\]|''|\\.)*'|` +
+		`\b0[xX][0-9a-fA-F]+\b|` +
+		`(?:\b[0-9]+(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][+-]?[0-9]+)?\b`)
+	sqlListPattern = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+)
+
+// NormalizeSQL replaces the string and number literals of sql with placeholders, collapses
+// placeholder lists and whitespace, so that it's safe to log and groups alike statements.
+// The $1 and ? placeholders are kept as they are.
+func NormalizeSQL(sql string, dialect SQLDialect) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range sqlTokenPattern.FindAllStringIndex(sql, -1) {
+		start, end := loc[0], loc[1]
+		token := sql[start:end]
+		switch {
+		case token[0] == '$':
+			continue
+		case token[0] == '"' && dialect != DialectMySQL:
+			continue
+		case isSignOf(sql, start):
+			start--
+		}
+		b.WriteString(sql[last:start])
+		b.WriteByte('?')
+		last = end
+	}
+	b.WriteString(sql[last:])
+	sql = strings.Join(strings.Fields(b.String()), " ")
+	return sqlListPattern.ReplaceAllString(sql, "(?)")
+}
+
+// isSignOf returns whether the number at start of sql is preceded by a minus sign rather than
+// by a subtraction, e.g. "= -5" but not "a-5" or "(a) - 5".
+func isSignOf(sql string, start int) bool {
+	if start == 0 || sql[start-1] != '-' {
+		return false
+	}
+	before := strings.TrimRight(sql[:start-1], " \t\r\n")
+	if before == "" {
+		return true
+	}
+	switch c := before[len(before)-1]; {
+	case c == ')' || c == '_' || c == '\'' || c == '"' || c == '`' || c == '?':
+		return false
+	case c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return false
+	}
+	return true
+}
+
+// Caller returns the file:line of the first frame of the call stack outside of this module
+// and of the packages prefixed by skipPackages, e.g. "gorm.io/gorm".
+func Caller(skipPackages ...string) string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isSkippedFrame(frame.Function, skipPackages) {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// modulePath is the path of this module, whose packages are skipped by Caller.
+const modulePath = "github.com/feymanlee/monitorit"
+
+func isSkippedFrame(function string, skipPackages []string) bool {
+	if strings.HasPrefix(function, modulePath+".") || strings.HasPrefix(function, modulePath+"/") ||
+		strings.HasPrefix(function, "runtime.") {
+		return true
+	}
+	for _, pkg := range skipPackages {
+		if strings.HasPrefix(function, pkg) {
+			return true
+		}
+	}
+	return false
+}
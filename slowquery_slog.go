@@ -0,0 +1,33 @@
+//
+// Package monitorit
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  slowquery_slog
+// @Date: 2026/10/17 14:02
+//
+
+//go:build go1.21
+
+package monitorit
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewSlogSink returns a sink logging slow queries to logger at warn level.
+func NewSlogSink(logger *slog.Logger) SlowQuerySink {
+	return SlowQuerySinkFunc(func(ctx context.Context, query SlowQuery) {
+		attrs := []slog.Attr{
+			slog.String("name", query.Name),
+			slog.String("command", query.Command),
+			slog.String("statement", query.Statement),
+			slog.Duration("duration", query.Duration),
+			slog.String("caller", query.Caller),
+		}
+		if query.Err != nil {
+			attrs = append(attrs, slog.String("error", query.Err.Error()))
+		}
+		logger.LogAttrs(ctx, slog.LevelWarn, "slow query", attrs...)
+	})
+}
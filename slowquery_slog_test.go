@@ -0,0 +1,84 @@
+//go:build go1.21
+
+package monitorit_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/feymanlee/monitorit"
+)
+
+// recordHandler keeps the records logged to it.
+type recordHandler struct {
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(_ context.Context, record slog.Record) error {
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordHandler) WithGroup(string) slog.Handler { return h }
+
+func TestSlogSink(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		query monitorit.SlowQuery
+		want  map[string]string
+	}{
+		{
+			name: "success",
+			query: monitorit.SlowQuery{
+				Name: "users", Command: "select", Statement: "SELECT ?", Duration: time.Second, Caller: "main.go:10",
+			},
+			want: map[string]string{
+				"name": "users", "command": "select", "statement": "SELECT ?", "duration": "1s", "caller": "main.go:10",
+			},
+		},
+		{
+			name: "error",
+			query: monitorit.SlowQuery{
+				Name: "users", Command: "select", Statement: "SELECT ?", Duration: time.Second, Caller: "main.go:10",
+				Err: errors.New("timeout"),
+			},
+			want: map[string]string{
+				"name": "users", "command": "select", "statement": "SELECT ?", "duration": "1s", "caller": "main.go:10",
+				"error": "timeout",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &recordHandler{}
+			monitorit.NewSlogSink(slog.New(handler)).Record(context.Background(), tt.query)
+
+			if len(handler.records) != 1 {
+				t.Fatalf("%d records logged, want 1", len(handler.records))
+			}
+			record := handler.records[0]
+			if record.Level != slog.LevelWarn {
+				t.Errorf("level = %v, want %v", record.Level, slog.LevelWarn)
+			}
+			got := make(map[string]string)
+			record.Attrs(func(attr slog.Attr) bool {
+				got[attr.Key] = attr.Value.String()
+				return true
+			})
+			if len(got) != len(tt.want) {
+				t.Errorf("attrs = %v, want %v", got, tt.want)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("attr %s = %q, want %q", key, got[key], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package monitorit_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/feymanlee/monitorit"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		sql     string
+		dialect monitorit.SQLDialect
+		want    string
+	}{
+		{"string", "SELECT * FROM users WHERE name = 'alice'", monitorit.DialectStandard, "SELECT * FROM users WHERE name = ?"},
+		{"doubled quote", "SELECT 'it''s secret'", monitorit.DialectStandard, "SELECT ?"},
+		{"backslash escape", `SELECT E'it\'s secret' FROM t`, monitorit.DialectStandard, "SELECT ? FROM t"},
+		{"escaped backslash", `SELECT 'C:\\' FROM t WHERE a = 'b'`, monitorit.DialectMySQL, "SELECT ? FROM t WHERE a = ?"},
+		{"national string", "SELECT N'secret'", monitorit.DialectStandard, "SELECT ?"},
+		{"mysql double quotes", `SELECT * FROM users WHERE email = "alice@example.com"`, monitorit.DialectMySQL, "SELECT * FROM users WHERE email = ?"},
+		{"mysql escaped double quote", `SELECT "say \"hi\"", 1`, monitorit.DialectMySQL, "SELECT ?, ?"},
+		{"quoted identifiers", `SELECT "user"."id2" FROM "user"`, monitorit.DialectStandard, `SELECT "user"."id2" FROM "user"`},
+		{"integer", "SELECT * FROM t LIMIT 10", monitorit.DialectStandard, "SELECT * FROM t LIMIT ?"},
+		{"decimal", "UPDATE t SET price = 9.99", monitorit.DialectStandard, "UPDATE t SET price = ?"},
+		{"exponent", "SELECT 1e10, 2.5E-3", monitorit.DialectStandard, "SELECT ?, ?"},
+		{"hex", "SELECT 0xFF, X'ff'", monitorit.DialectStandard, "SELECT ?, ?"},
+		{"negative", "SELECT * FROM t WHERE a = -5 AND b IN (-1, 2)", monitorit.DialectStandard, "SELECT * FROM t WHERE a = ? AND b IN (?)"},
+		{"subtraction", "SELECT a-5, (a) - 1 FROM t", monitorit.DialectStandard, "SELECT a-?, (a) - ? FROM t"},
+		{"identifiers with digits", "SELECT col1 FROM t2", monitorit.DialectStandard, "SELECT col1 FROM t2"},
+		{"postgres placeholders", "SELECT * FROM t WHERE a = $1 AND b = $12", monitorit.DialectStandard, "SELECT * FROM t WHERE a = $1 AND b = $12"},
+		{"question mark placeholders", "SELECT * FROM t WHERE a = ? AND b = ?", monitorit.DialectMySQL, "SELECT * FROM t WHERE a = ? AND b = ?"},
+		{"list", "SELECT * FROM t WHERE id IN (1, 2,3)", monitorit.DialectStandard, "SELECT * FROM t WHERE id IN (?)"},
+		{"whitespace", "SELECT *\n\tFROM  t", monitorit.DialectStandard, "SELECT * FROM t"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := monitorit.NormalizeSQL(tt.sql, tt.dialect); got != tt.want {
+				t.Errorf("NormalizeSQL(%q) = %q, want %q", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
+
+// callerOf calls Caller from a helper, as the hooks do from the library running the statement,
+// and returns the file:line of its call too.
+func callerOf(skipPackages ...string) (caller string, call string) {
+	_, file, line, _ := runtime.Caller(0)
+	return monitorit.Caller(skipPackages...), fmt.Sprintf("%s:%d", file, line+1)
+}
+
+func TestCaller(t *testing.T) {
+	if got, want := callerOf(); got != want {
+		t.Errorf("Caller() = %q, want the helper %q", got, want)
+	}
+
+	_, file, line, _ := runtime.Caller(0)
+	got, _ := callerOf("github.com/feymanlee/monitorit_test.callerOf")
+	if want := fmt.Sprintf("%s:%d", file, line+1); got != want {
+		t.Errorf("Caller() skipping the helper = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,35 @@
+//
+// Package xorm
+// @Author: feymanlee@gmail.com
+// @Description:
+// @File:  classifier
+// @Date: 2026/10/17 11:05
+//
+
+package xorm
+
+import (
+	"errors"
+
+	"github.com/feymanlee/monitorit"
+	"xorm.io/xorm"
+)
+
+// ErrorClassifier returns the default classifier of xorm errors, it recognizes the xorm
+// sentinel errors and falls back to monitorit.SQLErrorClassifier.
+func ErrorClassifier() monitorit.ErrorClassifier {
+	return monitorit.ChainErrorClassifiers(
+		monitorit.ErrorClassifierFunc(classifyXormError),
+		monitorit.SQLErrorClassifier(),
+	)
+}
+
+func classifyXormError(err error) string {
+	switch {
+	case errors.Is(err, xorm.ErrNotExist):
+		return monitorit.ErrorClassNotFound
+	case errors.Is(err, xorm.ErrTableNotFound):
+		return monitorit.ErrorClassSyntax
+	}
+	return monitorit.ErrorClassOther
+}
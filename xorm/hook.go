@@ -13,16 +13,17 @@ import (
 	"strings"
 
 	"github.com/feymanlee/monitorit"
-	"github.com/prometheus/client_golang/prometheus"
 	"xorm.io/xorm/contexts"
 )
 
 type Hook struct {
 	options        *Options
 	instanceName   string
-	queryHistogram *prometheus.HistogramVec
-	queryCounter   *prometheus.CounterVec
-	errorCounter   *prometheus.CounterVec
+	queryHistogram monitorit.Histogram
+	queryCounter   monitorit.Counter
+	errorCounter   monitorit.Counter
+	rowsHistogram  monitorit.Histogram
+	slowCounter    monitorit.Counter
 }
 
 var (
@@ -30,36 +31,72 @@ var (
 	errorLabelNames = []string{"db_name", "command", "error"}
 )
 
-func NewHook(dbName string, opts ...Option) *Hook {
+func NewHook(dbName string, opts ...Option) (*Hook, error) {
 	options := DefaultOptions()
 	options.Merge(opts...)
 	c := Hook{
 		options:      options,
 		instanceName: dbName,
 	}
-	c.queryHistogram = monitorit.Register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: options.Namespace,
-		Subsystem: options.Subsystem,
-		Name:      "query_duration_sec",
-		Help:      "Histogram of xorm query duration in seconds",
-		Buckets:   options.DurationBuckets,
-	}, queryLabelNames)).(*prometheus.HistogramVec)
+	backend := options.backend()
+	var err error
+	c.queryHistogram, err = backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "query_duration_sec",
+		OTelName:   "db.client.operation.duration",
+		Help:       "Histogram of xorm query duration in seconds",
+		Unit:       "s",
+		LabelNames: queryLabelNames,
+		Buckets:    options.DurationBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	c.queryCounter = monitorit.Register(prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: options.Namespace,
-		Subsystem: options.Subsystem,
-		Name:      "query_total",
-		Help:      "Number of xorm queries total",
-	}, queryLabelNames)).(*prometheus.CounterVec)
+	c.queryCounter, err = backend.NewCounter(monitorit.MetricOpts{
+		Name:       "query_total",
+		OTelName:   "db.client.operations",
+		Help:       "Number of xorm queries total",
+		Unit:       "{operation}",
+		LabelNames: queryLabelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	c.errorCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "query_err_total",
-			Help: "Total number of xorm query errors",
-		},
-		errorLabelNames,
-	)
-	return &c
+	c.errorCounter, err = backend.NewCounter(monitorit.MetricOpts{
+		Name:       "query_err_total",
+		OTelName:   "db.client.operation.errors",
+		Help:       "Total number of xorm query errors",
+		Unit:       "{error}",
+		LabelNames: errorLabelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.rowsHistogram, err = backend.NewHistogram(monitorit.MetricOpts{
+		Name:       "rows_affected",
+		OTelName:   "db.client.response.returned_rows",
+		Help:       "Histogram of rows affected by xorm statements",
+		Unit:       "{row}",
+		LabelNames: queryLabelNames,
+		Buckets:    options.RowsBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.slowCounter, err = backend.NewCounter(monitorit.MetricOpts{
+		Name:       "slow_queries_total",
+		OTelName:   "db.client.operation.slow",
+		Help:       "Number of xorm queries slower than the slow threshold",
+		Unit:       "{operation}",
+		LabelNames: queryLabelNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
 }
 
 func (h *Hook) BeforeProcess(c *contexts.ContextHook) (context.Context, error) {
@@ -68,10 +105,29 @@ func (h *Hook) BeforeProcess(c *contexts.ContextHook) (context.Context, error) {
 
 func (h *Hook) AfterProcess(c *contexts.ContextHook) error {
 	queryType := h.getQueryType(c.SQL)
-	h.queryCounter.WithLabelValues(h.instanceName, queryType).Inc()
-	h.queryHistogram.WithLabelValues(h.instanceName, queryType).Observe(c.ExecuteTime.Seconds())
+	h.queryCounter.Add(c.Ctx, 1, h.instanceName, queryType)
+	h.queryHistogram.Observe(c.Ctx, c.ExecuteTime.Seconds(), h.instanceName, queryType)
+	// Only executed statements have a result, queries don't
+	if c.Result != nil {
+		if rows, err := c.Result.RowsAffected(); err == nil {
+			h.rowsHistogram.Observe(c.Ctx, float64(rows), h.instanceName, queryType)
+		}
+	}
 	if c.Err != nil {
-		h.errorCounter.WithLabelValues(h.instanceName, queryType, c.Err.Error()).Inc()
+		h.errorCounter.Add(c.Ctx, 1, h.instanceName, queryType, h.options.ErrorClassifier.Classify(c.Err))
+	}
+	if h.options.SlowThreshold > 0 && c.ExecuteTime >= h.options.SlowThreshold {
+		h.slowCounter.Add(c.Ctx, 1, h.instanceName, queryType)
+		if h.options.SlowQuerySink != nil {
+			h.options.SlowQuerySink.Record(c.Ctx, monitorit.SlowQuery{
+				Name:      h.instanceName,
+				Command:   queryType,
+				Statement: monitorit.NormalizeSQL(c.SQL, h.options.SQLDialect),
+				Duration:  c.ExecuteTime,
+				Err:       c.Err,
+				Caller:    monitorit.Caller("xorm.io/"),
+			})
+		}
 	}
 	return nil
 }
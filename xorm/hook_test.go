@@ -0,0 +1,163 @@
+package xorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"xorm.io/xorm"
+	"xorm.io/xorm/contexts"
+)
+
+func TestHookQueryErrors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	hook, err := NewHook("test", WithRegisterer(registry))
+	if err != nil {
+		t.Fatalf("NewHook() error = %v", err)
+	}
+	engine, err := xorm.NewEngine("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("xorm.NewEngine() error = %v", err)
+	}
+	defer engine.Close()
+	engine.AddHook(hook)
+
+	if _, err := engine.Exec("SELECT FROM"); err == nil {
+		t.Fatal("Exec() of an invalid statement succeeded")
+	}
+	if _, err := engine.QueryString("SELECT * FROM missing"); err == nil {
+		t.Fatal("QueryString() in a missing table succeeded")
+	}
+
+	expected := `
+# HELP service_component_xorm_query_err_total Total number of xorm query errors
+# TYPE service_component_xorm_query_err_total counter
+service_component_xorm_query_err_total{command="select",db_name="test",error="syntax"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "service_component_xorm_query_err_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHookOpenTelemetry(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	hook, err := NewHook("users", WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	if err != nil {
+		t.Fatalf("NewHook() error = %v", err)
+	}
+	ctx := context.Background()
+	if err := hook.AfterProcess(&contexts.ContextHook{
+		Ctx:         ctx,
+		SQL:         "UPDATE users SET name = ? WHERE id = ?",
+		Result:      driver.RowsAffected(2),
+		ExecuteTime: time.Millisecond,
+	}); err != nil {
+		t.Fatalf("AfterProcess() error = %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	histograms := make(map[string]metricdata.HistogramDataPoint[float64])
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if data, ok := m.Data.(metricdata.Histogram[float64]); ok {
+			histograms[m.Name] = data.DataPoints[0]
+		}
+	}
+	for _, name := range []string{"db.client.operation.duration", "db.client.response.returned_rows"} {
+		point, ok := histograms[name]
+		if !ok {
+			t.Errorf("instrument %s wasn't collected, got %v", name, histograms)
+			continue
+		}
+		if value, _ := point.Attributes.Value("db.namespace"); value.AsString() != "users" {
+			t.Errorf("%s db.namespace = %q, want %q", name, value.AsString(), "users")
+		}
+		if value, _ := point.Attributes.Value("db.operation.name"); value.AsString() != "update" {
+			t.Errorf("%s db.operation.name = %q, want %q", name, value.AsString(), "update")
+		}
+	}
+	if got := histograms["db.client.response.returned_rows"].Sum; got != 2 {
+		t.Errorf("db.client.response.returned_rows sum = %v, want 2", got)
+	}
+}
+
+func TestHookRowsAffected(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	hook, err := NewHook("test", WithRegisterer(registry), WithRowsBuckets([]float64{1}))
+	if err != nil {
+		t.Fatalf("NewHook() error = %v", err)
+	}
+	engine, err := xorm.NewEngine("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("xorm.NewEngine() error = %v", err)
+	}
+	defer engine.Close()
+	if _, err := engine.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatalf("CREATE TABLE error = %v", err)
+	}
+	engine.AddHook(hook)
+
+	if _, err := engine.Exec("INSERT INTO users (id, name) VALUES (1, 'alice'), (2, 'bob'), (3, 'carol')"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	// Queries have no result
+	if _, err := engine.QueryString("SELECT * FROM users"); err != nil {
+		t.Fatalf("QueryString() error = %v", err)
+	}
+
+	expected := `
+# HELP service_component_xorm_rows_affected Histogram of rows affected by xorm statements
+# TYPE service_component_xorm_rows_affected histogram
+service_component_xorm_rows_affected_bucket{command="insert",db_name="test",le="1"} 0
+service_component_xorm_rows_affected_bucket{command="insert",db_name="test",le="+Inf"} 1
+service_component_xorm_rows_affected_sum{command="insert",db_name="test"} 3
+service_component_xorm_rows_affected_count{command="insert",db_name="test"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "service_component_xorm_rows_affected"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHookNativeHistograms(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	hook, err := NewHook("test", WithRegisterer(registry), WithNativeHistograms(1.1, 100))
+	if err != nil {
+		t.Fatalf("NewHook() error = %v", err)
+	}
+	if err := hook.AfterProcess(&contexts.ContextHook{
+		Ctx:         context.Background(),
+		SQL:         "DELETE FROM users",
+		Result:      driver.RowsAffected(2),
+		ExecuteTime: time.Millisecond,
+	}); err != nil {
+		t.Fatalf("AfterProcess() error = %v", err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	var histograms int
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if h := metric.GetHistogram(); h != nil {
+				histograms++
+				if h.Schema == nil {
+					t.Errorf("%s isn't a native histogram", family.GetName())
+				}
+			}
+		}
+	}
+	if histograms != 2 {
+		t.Errorf("histograms = %d, want the query duration and rows affected", histograms)
+	}
+}